@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/shiquda/treex/pkg/treex"
+	flag "github.com/spf13/pflag"
+)
+
+// Run the check subcommand: treex check [options] <spec>. Returns the process
+// exit code: 0 when the layout matches, 1 on violations, 2 on errors and 3
+// when some directories couldn't be read.
+func runCheck(args []string) int {
+	opts := &Options{}
+	fs := newFlagSet("treex check", opts)
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		fs.Usage()
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "error: usage: treex check [options] <spec>\n")
+		return 2
+	}
+
+	spec, err := treex.LoadLayoutSpec(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error reading spec: %s\n", err)
+		return 2
+	}
+
+	node, err := buildTree(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		return 2
+	}
+
+	violations := treex.CheckLayout(node, spec)
+	printViolations(os.Stdout, violations)
+	readErrors := printReadErrors(os.Stderr, node)
+	if len(violations) > 0 {
+		return 1
+	}
+	if readErrors {
+		return 3
+	}
+	return 0
+}
+
+func printViolations(w io.Writer, violations []treex.LayoutViolation) {
+	for _, v := range violations {
+		fmt.Fprintln(w, v)
+	}
+	if len(violations) == 0 {
+		fmt.Fprintln(w, "layout OK")
+	} else {
+		fmt.Fprintf(w, "%d violation(s)\n", len(violations))
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunCheck(t *testing.T) {
+	tempDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tempDir, "cmd"), 0755)
+	os.WriteFile(filepath.Join(tempDir, "go.mod"), []byte("module x"), 0644)
+
+	specPath := filepath.Join(tempDir, "spec.json")
+	os.WriteFile(specPath, []byte(`{"required": ["cmd/", "go.mod"]}`), 0644)
+	if code := runCheck([]string{"-d", tempDir, specPath}); code != 0 {
+		t.Errorf("Expected exit code 0, but got %d", code)
+	}
+
+	os.WriteFile(specPath, []byte(`{"required": ["docs/"]}`), 0644)
+	if code := runCheck([]string{"-d", tempDir, specPath}); code != 1 {
+		t.Errorf("Expected exit code 1 for violations, but got %d", code)
+	}
+
+	if code := runCheck([]string{"-d", tempDir, filepath.Join(tempDir, "missing.json")}); code != 2 {
+		t.Errorf("Expected exit code 2 for a missing spec, but got %d", code)
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Matches a generated block: <!-- treex:start [options] --> ... <!-- treex:end -->
+var injectBlockPattern = regexp.MustCompile(`(?s)(<!--\s*treex:start\b(.*?)-->)(.*?)(<!--\s*treex:end\s*-->)`)
+
+// Regenerate the treex blocks in each file, relative to the file's directory.
+// With check, files are left untouched and out-of-date blocks are reported.
+// Returns the process exit code: 0 when up to date, 1 when a block is out of
+// date in check mode and 2 on errors.
+func runInject(files []string, check bool) int {
+	exitCode := 0
+	for _, file := range files {
+		changed, err := injectFile(file, check)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s: %s\n", file, err)
+			exitCode = 2
+			continue
+		}
+		if !changed {
+			continue
+		}
+
+		if check {
+			fmt.Fprintf(os.Stderr, "%s: treex block is out of date\n", file)
+			if exitCode == 0 {
+				exitCode = 1
+			}
+		} else {
+			fmt.Printf("Updated: %s\n", file)
+		}
+	}
+	return exitCode
+}
+
+func injectFile(file string, check bool) (bool, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return false, err
+	}
+
+	absoluteFile, err := filepath.Abs(file)
+	if err != nil {
+		return false, err
+	}
+
+	// Options in markers are relative to the file that contains them
+	oldWd, err := os.Getwd()
+	if err != nil {
+		return false, err
+	}
+	if err := os.Chdir(filepath.Dir(absoluteFile)); err != nil {
+		return false, err
+	}
+	newContent, err := injectTrees(string(content), generate)
+	os.Chdir(oldWd)
+	if err != nil {
+		return false, err
+	}
+
+	if newContent == string(content) {
+		return false, nil
+	}
+	if !check {
+		if err := os.WriteFile(absoluteFile, []byte(newContent), 0644); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// Replace the body of every treex block in content with the output of
+// generateFunc for the options given in the block's start marker.
+func injectTrees(content string, generateFunc func(*Options) (string, error)) (string, error) {
+	matches := injectBlockPattern.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no treex:start/treex:end block found")
+	}
+
+	var result strings.Builder
+	last := 0
+	for _, m := range matches {
+		startMarker := content[m[2]:m[3]]
+		markerArgs := content[m[4]:m[5]]
+		endMarker := content[m[8]:m[9]]
+
+		opts, err := parseMarkerOptions(markerArgs)
+		if err != nil {
+			return "", fmt.Errorf("invalid marker %q: %w", startMarker, err)
+		}
+		output, err := generateFunc(opts)
+		if err != nil {
+			return "", err
+		}
+
+		result.WriteString(content[last:m[0]])
+		result.WriteString(startMarker)
+		result.WriteString("\n")
+		result.WriteString(wrapInjectedOutput(opts.OutputFormat, output))
+		result.WriteString(endMarker)
+		last = m[1]
+	}
+	result.WriteString(content[last:])
+
+	return result.String(), nil
+}
+
+// Parse the options embedded in a start marker, e.g. "-f tree -I"
+func parseMarkerOptions(markerArgs string) (*Options, error) {
+	args, err := splitArgs(markerArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &Options{}
+	fs := newFlagSet("treex:start", opts)
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if len(opts.Inject) > 0 || opts.OutputFilePath != "" {
+		return nil, fmt.Errorf("--inject and --output can't be used in a marker")
+	}
+	// The report's total size changes whenever a file inside the scanned
+	// tree is rewritten, including the file being injected, so it is left
+	// out unless the marker asks for it with --no-report=false
+	if !fs.Changed("no-report") {
+		opts.NoReport = true
+	}
+	return opts, nil
+}
+
+// Wrap plain-text formats in a fenced code block so they render in Markdown
+func wrapInjectedOutput(format string, output string) string {
+	if !strings.HasSuffix(output, "\n") {
+		output += "\n"
+	}
+
+	switch format {
+	case "tree", "indent":
+		return "```text\n" + output + "```\n"
+	case "mermaid":
+		return "```mermaid\n" + output + "```\n"
+	default:
+		return output
+	}
+}
+
+// Split a command line into arguments, honoring single and double quotes
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
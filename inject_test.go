@@ -0,0 +1,160 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected []string
+	}{
+		{"", nil},
+		{" -f tree -I ", []string{"-f", "tree", "-I"}},
+		{`-e ".git/, .md" -d 'my dir'`, []string{"-e", ".git/, .md", "-d", "my dir"}},
+		{`-e ""`, []string{"-e", ""}},
+	}
+
+	for _, tc := range testCases {
+		result, err := splitArgs(tc.input)
+		if err != nil {
+			t.Errorf("For %q, unexpected error: %v", tc.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(result, tc.expected) {
+			t.Errorf("For %q, expected %q, but got %q", tc.input, tc.expected, result)
+		}
+	}
+
+	if _, err := splitArgs(`-e "unterminated`); err == nil {
+		t.Error("Should fail on unterminated quotes")
+	}
+}
+
+func TestParseMarkerOptions(t *testing.T) {
+	opts, err := parseMarkerOptions(" -f md -I -m 2 ")
+	if err != nil {
+		t.Fatalf("parseMarkerOptions error: %v", err)
+	}
+	if opts.OutputFormat != "md" || !opts.UseGitIgnore || opts.MaxDepth != 2 || opts.Dir != "." {
+		t.Errorf("Unexpected options: %+v", opts)
+	}
+
+	if !opts.NoReport {
+		t.Error("Blocks should leave out the report by default")
+	}
+	if opts, _ := parseMarkerOptions("--no-report=false"); opts.NoReport {
+		t.Error("Markers should be able to ask for the report")
+	}
+
+	if _, err := parseMarkerOptions("--unknown"); err == nil {
+		t.Error("Should fail on unknown flags")
+	}
+	if _, err := parseMarkerOptions("-o out.txt"); err == nil {
+		t.Error("Should not allow --output in markers")
+	}
+}
+
+func TestInjectTrees(t *testing.T) {
+	content := `# Project
+
+<!-- treex:start -f tree -->
+stale
+<!-- treex:end -->
+
+Text in between
+
+<!--treex:start -f md-->
+<!-- treex:end -->
+`
+
+	generateFunc := func(opts *Options) (string, error) {
+		return "output for " + opts.OutputFormat, nil
+	}
+
+	result, err := injectTrees(content, generateFunc)
+	if err != nil {
+		t.Fatalf("injectTrees error: %v", err)
+	}
+
+	expected := "# Project\n\n<!-- treex:start -f tree -->\n```text\noutput for tree\n```\n<!-- treex:end -->\n\n" +
+		"Text in between\n\n<!--treex:start -f md-->\noutput for md\n<!-- treex:end -->\n"
+	if result != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result)
+	}
+
+	// Injecting again should not change anything
+	again, err := injectTrees(result, generateFunc)
+	if err != nil {
+		t.Fatalf("injectTrees error: %v", err)
+	}
+	if again != result {
+		t.Errorf("Injection should be idempotent, got:\n%s", again)
+	}
+
+	if _, err := injectTrees("no markers here", generateFunc); err == nil {
+		t.Error("Should fail when there is no treex block")
+	}
+}
+
+func TestInjectFile(t *testing.T) {
+	tempDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tempDir, "src"), 0755)
+	os.WriteFile(filepath.Join(tempDir, "src", "main.go"), []byte("package main"), 0644)
+
+	readme := filepath.Join(tempDir, "README.md")
+	os.WriteFile(readme, []byte("<!-- treex:start -d src -->\n<!-- treex:end -->\n"), 0644)
+
+	// Check mode reports the stale block without touching the file
+	if code := runInject([]string{readme}, true); code != 1 {
+		t.Errorf("Expected exit code 1 for an out-of-date block, but got %d", code)
+	}
+	content, _ := os.ReadFile(readme)
+	if strings.Contains(string(content), "main.go") {
+		t.Error("Check mode should not modify the file")
+	}
+
+	// Paths in markers are relative to the file
+	if code := runInject([]string{readme}, false); code != 0 {
+		t.Errorf("Expected exit code 0, but got %d", code)
+	}
+	content, _ = os.ReadFile(readme)
+	if !strings.Contains(string(content), "src/\n└── main.go\n") {
+		t.Errorf("Block should contain the regenerated tree:\n%s", content)
+	}
+
+	if code := runInject([]string{readme}, true); code != 0 {
+		t.Errorf("Expected exit code 0 for an up-to-date block, but got %d", code)
+	}
+
+	if code := runInject([]string{filepath.Join(tempDir, "missing.md")}, true); code != 2 {
+		t.Errorf("Expected exit code 2 for a missing file, but got %d", code)
+	}
+}
+
+func TestInjectFileInScannedTree(t *testing.T) {
+	tempDir := t.TempDir()
+	os.WriteFile(filepath.Join(tempDir, "go.mod"), []byte("module example"), 0644)
+
+	// The file is part of the tree it documents, so rewriting it mustn't
+	// make the block stale again
+	readme := filepath.Join(tempDir, "README.md")
+	os.WriteFile(readme, []byte("# Example\n\n<!-- treex:start -->\n<!-- treex:end -->\n"), 0644)
+
+	if code := runInject([]string{readme}, false); code != 0 {
+		t.Fatalf("Expected exit code 0, but got %d", code)
+	}
+	injected, _ := os.ReadFile(readme)
+
+	if code := runInject([]string{readme}, true); code != 0 {
+		t.Errorf("Expected the block to be up to date after injecting, but got exit code %d:\n%s", code, injected)
+	}
+	runInject([]string{readme}, false)
+	if again, _ := os.ReadFile(readme); string(again) != string(injected) {
+		t.Errorf("Injecting again should not change the file, got:\n%s", again)
+	}
+}
@@ -1,80 +1,327 @@
 package main
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/shiquda/treex/pkg/treex"
 	flag "github.com/spf13/pflag"
 )
 
+// Options holds the command-line options of a treex run
+type Options struct {
+	treex.Options
+	OutputFormat   string
+	OutputFilePath string
+	UseIcons       bool
+	ShowSizes      bool
+	ShowPerms      bool
+	ShowOwner      bool
+	ShowGroup      bool
+	ShowInodes     bool
+	ShowHardLinks  bool
+	NoReport       bool
+	TemplatePath   string
+	MDLinks        bool
+	BaseURL        string
+	Inject         []string
+	Check          bool
+	InputFormat    string
+	InputFilePath  string
+	FromStdin      bool
+	Color          string
+	colors         *treex.ColorScheme // set for terminal output only, so injected blocks stay plain
+}
+
+// Create a flag set that parses command-line options into opts
+func newFlagSet(name string, opts *Options) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s:\n", name)
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nFormats:\n")
+		for _, f := range treex.Formats() {
+			fmt.Fprintf(os.Stderr, "  %-12s %s\n", f.Name, f.Description)
+		}
+	}
+	fs.StringVarP(&opts.Dir, "dir", "d", ".", "directory or archive (zip, tar, tar.gz) to scan")
+	fs.StringVarP(&opts.OutputFormat, "format", "f", "tree", "output format. allowed: ["+strings.Join(treex.FormatNames(), ", ")+"]")
+	fs.IntVarP(&opts.MaxDepth, "max-depth", "m", 0, "maximum directory depth (0 for unlimited)")
+	fs.StringVarP(&opts.OutputFilePath, "output", "o", "", "output file path (default: stdout)")
+	fs.StringVarP(&opts.Exclude, "exclude", "e", "", "exclude rules (comma-separated, e.g. 'dir/, .txt')")
+	fs.BoolVarP(&opts.HideHidden, "hide-hidden", "H", false, "hide hidden files and directories (default: false)")
+	fs.BoolVarP(&opts.DirsOnly, "dirs-only", "D", false, "show directories only (default: false)")
+	fs.BoolVarP(&opts.UseGitIgnore, "use-gitignore", "I", false, "use .gitignore patterns to exclude files/directories (default: false)")
+	fs.BoolVarP(&opts.UseIcons, "icons", "C", false, "display file type icons (default: false)")
+	fs.BoolVarP(&opts.ShowSizes, "sizes", "s", false, "display human-readable sizes before entries (default: false)")
+	fs.BoolVarP(&opts.ShowPerms, "perms", "p", false, "display permissions before entries, e.g. drwxr-xr-x (default: false)")
+	fs.BoolVarP(&opts.ShowOwner, "owner", "u", false, "display owner names before entries (default: false)")
+	fs.BoolVarP(&opts.ShowGroup, "group", "g", false, "display group names before entries (default: false)")
+	fs.BoolVar(&opts.ShowInodes, "inodes", false, "display inode numbers before entries (default: false)")
+	fs.BoolVar(&opts.ShowHardLinks, "hard-links", false, "display hard link counts before entries (default: false)")
+	fs.BoolVar(&opts.NoReport, "no-report", false, "don't end the output with the number of directories and files (default: false)")
+	fs.StringVar(&opts.TemplatePath, "template", "", "Go text/template file used by the template format")
+	fs.BoolVar(&opts.MDLinks, "md-links", false, "link every entry to its relative path in md format (default: false)")
+	fs.StringVar(&opts.BaseURL, "base-url", "", "URL prefix for links generated by --md-links")
+	fs.BoolVarP(&opts.Annotate, "annotate", "a", false, "show descriptions from the .treexdesc file in the scanned directory (default: false)")
+	fs.StringVar(&opts.DescFilePath, "desc-file", "", "description file mapping paths to text (implies --annotate)")
+	fs.BoolVar(&opts.AutoDesc, "auto-desc", false, "derive directory descriptions from package docs, READMEs and manifests (default: false)")
+	fs.StringSliceVar(&opts.Inject, "inject", nil, "regenerate the treex blocks in these files (comma-separated or repeated)")
+	fs.BoolVar(&opts.Check, "check", false, "with --inject, only report out-of-date blocks and exit non-zero (default: false)")
+	fs.StringVar(&opts.InputFormat, "input-format", "", "read a listing instead of scanning a directory. allowed: [tree, indent, md, json]")
+	fs.StringVar(&opts.InputFilePath, "input", "-", "listing file read by --input-format (default: stdin)")
+	fs.BoolVar(&opts.FromStdin, "from-stdin", false, "build the tree from a newline or NUL separated list of paths on stdin (default: false)")
+	fs.IntVarP(&opts.Jobs, "jobs", "j", 1, "number of directories to read concurrently")
+	fs.BoolVar(&opts.Compact, "compact", false, "merge chains of directories holding a single directory, e.g. src/main/java/ (default: false)")
+	fs.StringVar(&opts.Sort, "sort", "", "order of entries (default: name, or the input order with --input-format). allowed: ["+strings.Join(treex.SortOrders, ", ")+"]")
+	fs.BoolVarP(&opts.Reverse, "reverse", "r", false, "reverse the sort order (default: false)")
+	fs.BoolVar(&opts.DirsFirst, "dirs-first", false, "list directories before files (default: false)")
+	fs.BoolVar(&opts.FilesFirst, "files-first", false, "list files before directories (default: false)")
+	fs.BoolVar(&opts.IgnoreCase, "ignore-case", false, "sort names case-insensitively (default: false)")
+	fs.IntVar(&opts.MaxEntries, "max-entries", 0, "maximum number of entries shown per directory (0 for unlimited)")
+	fs.IntVar(&opts.MaxTotal, "max-total", 0, "maximum number of entries shown in all (0 for unlimited)")
+	fs.BoolVar(&opts.GroupElided, "group-elided", false, "describe the entries left out by --max-entries and --max-total by extension (default: false)")
+	fs.BoolVarP(&opts.FollowLinks, "follow", "l", false, "descend into symbolic links to directories, skipping links to parent directories (default: false)")
+	fs.StringVar(&opts.Color, "color", "auto", "color entries using LS_COLORS. allowed: [auto, always, never]")
+	return fs
+}
+
 func main() {
+	// subcommands
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "check":
+			os.Exit(runCheck(os.Args[2:]))
+		case "scaffold":
+			os.Exit(runScaffold(os.Args[2:]))
+		}
+	}
+
 	// parse flags
-	dir := flag.StringP("dir", "d", ".", "directory to scan")
-	outputFormat := flag.StringP("format", "f", "tree", "output format. allowed: [indent, tree, md, mermaid]")
-	maxDepth := flag.IntP("max-depth", "m", 0, "maximum directory depth (0 for unlimited)")
-	outputFilePath := flag.StringP("output", "o", "", "output file path (default: stdout)")
-	excludeRuleStr := flag.StringP("exclude", "e", "", "exclude rules (comma-separated, e.g. 'dir/, .txt')")
-	hideHidden := flag.BoolP("hide-hidden", "H", false, "hide hidden files and directories (default: false)")
-	dirsOnly := flag.BoolP("dirs-only", "D", false, "show directories only (default: false)")
-	useGitIgnore := flag.BoolP("use-gitignore", "I", false, "use .gitignore patterns to exclude files/directories (default: false)")
-	useIcons := flag.BoolP("icons", "C", false, "display file type icons (default: false)")
-	flag.Parse()
-
-	// get the absolute path and ensure it ends with "/"
-	absolutePath, err := os.Getwd()
-	if err != nil {
+	opts := &Options{}
+	fs := newFlagSet(os.Args[0], opts)
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		if err == flag.ErrHelp {
+			return
+		}
 		fmt.Fprintf(os.Stderr, "error: %s\n", err)
-		return
+		fs.Usage()
+		os.Exit(2)
 	}
-	if !strings.HasSuffix(absolutePath, "/") {
-		absolutePath += "/"
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "error: unexpected argument '%s'\n", fs.Arg(0))
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	// inject mode
+	if len(opts.Inject) > 0 {
+		os.Exit(runInject(opts.Inject, opts.Check))
 	}
 
-	// filters
-	filter := NewFilter(*excludeRuleStr, *useGitIgnore)
-	node, err := getTreeNode(*dir, 1, absolutePath, *maxDepth, filter, *hideHidden, *dirsOnly)
+	out := os.Stdout
+	if opts.OutputFilePath != "" {
+		out = nil
+	}
+	node, err := buildTree(opts)
+	var renderer treex.Renderer
+	if err == nil {
+		opts.colors, err = getColorScheme(opts.Color, out)
+	}
+	if err == nil {
+		renderer, err = newRenderer(opts)
+	}
+	// usage is only printed for flag errors, not for runtime failures
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %s\n", err)
-		flag.Usage()
-		return
-	}
-
-	// output
-	var outputStr string
-	switch *outputFormat {
-	case "tree":
-		outputStr = node.ToTreeString(true, "", *useIcons)
-	case "indent":
-		outputStr = node.ToIndentString(4, *useIcons)
-	case "md":
-		outputStr = node.ToMarkdownString(0, *useIcons)
-	case "mermaid":
-		outputStr = node.ToMermaidString()
-	default:
-		fmt.Fprintf(os.Stderr, "error: unknown outputFormat '%s'\n", *outputFormat)
-		flag.Usage()
-		return
+		os.Exit(2)
 	}
 
 	// write to file
-	if *outputFilePath != "" {
+	if opts.OutputFilePath != "" {
 		// create output dir (if not exist)
-		outputDirPath := filepath.Dir(*outputFilePath)
+		outputDirPath := filepath.Dir(opts.OutputFilePath)
 		if outputDirPath != "." {
 			if err := os.MkdirAll(outputDirPath, 0755); err != nil {
 				fmt.Fprintf(os.Stderr, "error creating output directory: %s\n", err)
-				return
+				os.Exit(2)
 			}
 		}
 
-		if err := os.WriteFile(*outputFilePath, []byte(outputStr), 0644); err != nil {
+		if err := writeOutputFile(opts.OutputFilePath, node, renderer); err != nil {
 			fmt.Fprintf(os.Stderr, "error writing to file: %s\n", err)
-			return
+			os.Exit(2)
 		}
-		fmt.Printf("Output written to: %s\n", *outputFilePath)
+		fmt.Printf("Output written to: %s\n", opts.OutputFilePath)
+	} else if err := writeOutput(os.Stdout, node, renderer); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(2)
+	}
+
+	// the tree is complete except for unreadable directories
+	if opts.InputFormat == "" && printReadErrors(os.Stderr, node) {
+		os.Exit(3)
+	}
+}
+
+// Print the errors of the directories that couldn't be read, like `tree`
+// does after the listing. Reports whether there were any.
+func printReadErrors(w io.Writer, node *treex.TreeNode) bool {
+	errs := node.Errors()
+	for _, err := range errs {
+		fmt.Fprintf(w, "error: %s\n", err)
+	}
+
+	switch len(errs) {
+	case 0:
+		return false
+	case 1:
+		fmt.Fprintf(w, "1 directory could not be read\n")
+	default:
+		fmt.Fprintf(w, "%d directories could not be read\n", len(errs))
+	}
+	return true
+}
+
+// Render a tree to w through a buffer, so output starts before the whole
+// tree is rendered without a system call per line
+func writeOutput(w io.Writer, node *treex.TreeNode, renderer treex.Renderer) error {
+	bw := bufio.NewWriter(w)
+	if err := renderer.Render(bw, node); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func writeOutputFile(filePath string, node *treex.TreeNode, renderer treex.Renderer) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	if err := writeOutput(f, node, renderer); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Build the tree described by opts and render it in the requested format
+func generate(opts *Options) (string, error) {
+	node, err := buildTree(opts)
+	if err != nil {
+		return "", err
+	}
+	if errs := node.Errors(); opts.InputFormat == "" && len(errs) > 0 {
+		return "", errors.Join(errs...)
+	}
+	return render(node, opts)
+}
+
+// Build the tree described by opts, including descriptions
+func buildTree(opts *Options) (*treex.TreeNode, error) {
+	if opts.InputFormat != "" {
+		node, err := readListing(opts.InputFilePath, opts.InputFormat)
+		if err != nil {
+			return nil, err
+		}
+		// Listings keep their own order unless --sort is given
+		listingOpts := opts.Options
+		if listingOpts.Sort == "" {
+			listingOpts.Sort = "none"
+		}
+		return node, node.Arrange(&listingOpts)
+	}
+
+	buildOpts := opts.Options
+	buildOpts.Metadata = buildOpts.Metadata || opts.ShowOwner || opts.ShowGroup || opts.ShowInodes || opts.ShowHardLinks
+	if opts.FromStdin {
+		paths, err := treex.ReadPathList(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("reading paths: %w", err)
+		}
+		buildOpts.Paths = paths
+	}
+	return treex.Build(&buildOpts)
+}
+
+// Read a saved listing in the given format from a file, or stdin for "-"
+func readListing(filePath string, format string) (*treex.TreeNode, error) {
+	var content []byte
+	var err error
+	if filePath == "-" {
+		content, err = io.ReadAll(os.Stdin)
 	} else {
-		fmt.Print(outputStr)
+		content, err = os.ReadFile(filePath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading input: %w", err)
+	}
+	return treex.ParseListing(string(content), format)
+}
+
+// Get the colors for the --color mode, or nil for plain output. With auto,
+// output is colored when out is a terminal, unless NO_COLOR is set or TERM is
+// dumb. TREEX_COLORS is applied on top of LS_COLORS.
+func getColorScheme(mode string, out *os.File) (*treex.ColorScheme, error) {
+	switch mode {
+	case "never":
+		return nil, nil
+	case "auto":
+		if out == nil || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+			return nil, nil
+		}
+		if info, err := out.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return nil, nil
+		}
+	case "always":
+	default:
+		return nil, fmt.Errorf("invalid --color '%s', allowed: auto, always, never", mode)
+	}
+	return treex.NewColorScheme(os.Getenv("LS_COLORS") + ":" + os.Getenv("TREEX_COLORS")), nil
+}
+
+// Render a tree in the format requested by opts
+func render(node *treex.TreeNode, opts *Options) (string, error) {
+	renderer, err := newRenderer(opts)
+	if err != nil {
+		return "", err
+	}
+
+	var result strings.Builder
+	if err := renderer.Render(&result, node); err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
+
+// Get the renderer of the format requested by opts
+func newRenderer(opts *Options) (treex.Renderer, error) {
+	renderOpts := treex.RenderOptions{
+		Icons:     opts.UseIcons,
+		Sizes:     opts.ShowSizes,
+		Perms:     opts.ShowPerms,
+		Owner:     opts.ShowOwner,
+		Group:     opts.ShowGroup,
+		Inode:     opts.ShowInodes,
+		HardLinks: opts.ShowHardLinks,
+		Links:     opts.MDLinks,
+		BaseURL:   opts.BaseURL,
+		Report:    !opts.NoReport,
+		Colors:    opts.colors,
+	}
+	if opts.TemplatePath != "" {
+		tmplText, err := os.ReadFile(opts.TemplatePath)
+		if err != nil {
+			return nil, fmt.Errorf("reading template: %w", err)
+		}
+		renderOpts.Template = string(tmplText)
+	} else if opts.OutputFormat == "template" {
+		return nil, fmt.Errorf("the template format requires --template")
 	}
+	return treex.NewRenderer(opts.OutputFormat, renderOpts)
 }
@@ -1,197 +1,168 @@
 package main
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/shiquda/treex/pkg/treex"
 )
 
-// Setup temporary directory structure for testing
-func setupTestDir(t *testing.T) string {
-	tempDir := filepath.Join(os.TempDir(), "treex_test")
-	os.RemoveAll(tempDir) // Clean up previous test directory
+func TestGenerate(t *testing.T) {
+	tempDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tempDir, "cmd"), 0755)
+	os.WriteFile(filepath.Join(tempDir, "cmd", "main.go"), []byte("package main"), 0644)
 
-	// Create directory structure
-	dirs := []string{
-		filepath.Join(tempDir, "dir1"),
-		filepath.Join(tempDir, "dir2", "subdir"),
-		filepath.Join(tempDir, ".hidden_dir"),
+	opts := &Options{}
+	fs := newFlagSet("treex", opts)
+	if err := fs.Parse([]string{"-d", tempDir, "-f", "indent", "--no-report"}); err != nil {
+		t.Fatalf("Parse error: %v", err)
 	}
 
-	for _, dir := range dirs {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			t.Fatalf("Failed to create test directory: %v", err)
-		}
+	result, err := generate(opts)
+	if err != nil {
+		t.Fatalf("generate error: %v", err)
+	}
+	if !strings.HasSuffix(result, "/\n    cmd/\n        main.go\n") {
+		t.Errorf("Unexpected output:\n%s", result)
 	}
 
-	// Create some files
-	files := map[string]string{
-		filepath.Join(tempDir, "file1.txt"):         "test content",
-		filepath.Join(tempDir, "dir1", "file2.go"):  "go code",
-		filepath.Join(tempDir, ".hidden_file"):      "hidden",
-		filepath.Join(tempDir, "dir2", "config.md"): "# Config",
+	// The report is printed by default
+	opts.NoReport = false
+	result, _ = generate(opts)
+	if !strings.HasSuffix(result, "main.go\n\n1 directory, 1 file, 12B total\n") {
+		t.Errorf("Expected a report, got:\n%s", result)
 	}
 
-	for path, content := range files {
-		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
-			t.Fatalf("Failed to create test file: %v", err)
+	for _, format := range []string{"tree", "md", "md-code", "md-table", "mermaid", "json", "treemap-svg"} {
+		opts.OutputFormat = format
+		if _, err := generate(opts); err != nil {
+			t.Errorf("%s: generate error: %v", format, err)
 		}
 	}
 
-	// Create files for gitignore test
-	ignoreContent := "*.log\nbuild/\n"
-	if err := os.WriteFile(filepath.Join(tempDir, ".gitignore"), []byte(ignoreContent), 0644); err != nil {
-		t.Fatalf("Failed to create .gitignore file: %v", err)
+	opts.OutputFormat = "template"
+	if _, err := generate(opts); err == nil {
+		t.Error("The template format should require --template")
 	}
 
-	// Create files that should be excluded
-	os.WriteFile(filepath.Join(tempDir, "log.log"), []byte("log"), 0644)
-	os.MkdirAll(filepath.Join(tempDir, "build"), 0755)
-	os.WriteFile(filepath.Join(tempDir, "build", "app.js"), []byte("js"), 0644)
-
-	return tempDir
+	opts.OutputFormat = "xml"
+	if _, err := generate(opts); err == nil {
+		t.Error("Should fail on unknown output formats")
+	}
 }
 
-// Integration test for different output types
-func TestIntegration(t *testing.T) {
-	testDir := setupTestDir(t)
-	defer os.RemoveAll(testDir)
+func TestReadListing(t *testing.T) {
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "listing.md")
+	os.WriteFile(filePath, []byte("- project/\n  - main.go\n"), 0644)
+
+	node, err := readListing(filePath, "md")
+	if err != nil {
+		t.Fatalf("readListing error: %v", err)
+	}
+	if node.Name != "project" || len(node.Children) != 1 {
+		t.Errorf("Unexpected tree:\n%s", node.ToTreeString(true, "", false))
+	}
+
+	if _, err := readListing(filepath.Join(tempDir, "missing.md"), "md"); err == nil {
+		t.Error("Should fail on missing files")
+	}
+}
 
-	// Ensure working directory is correct
-	oldWd, _ := os.Getwd()
-	os.Chdir(testDir)
-	defer os.Chdir(oldWd)
+func TestArrangeListing(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "listing.txt")
+	os.WriteFile(filePath, []byte("p/\n├── b\n├── a\n└── c/\n    ├── z\n    └── y\n"), 0644)
 
-	// Test scenarios
 	testCases := []struct {
-		name       string
-		filter     *Filter
-		hideHidden bool
-		dirsOnly   bool
-		maxDepth   int
-		checkFunc  func(node *TreeNode) bool
+		name     string
+		opts     treex.Options
+		expected string
 	}{
-		{
-			name:       "Basic Tree Generation",
-			filter:     NewFilter("", false),
-			hideHidden: false,
-			dirsOnly:   false,
-			maxDepth:   0,
-			checkFunc: func(node *TreeNode) bool {
-				// Check if all files and directories are included
-				allNodes := node.getAllNodes()
-				return len(allNodes) >= 10 // root + at least 9 child nodes
-			},
-		},
-		{
-			name:       "Hidden Files Filter",
-			filter:     NewFilter("", false),
-			hideHidden: true,
-			dirsOnly:   false,
-			maxDepth:   0,
-			checkFunc: func(node *TreeNode) bool {
-				// Check if hidden files are filtered
-				for _, child := range node.Children {
-					if child.Name == ".hidden_dir" || child.Name == ".hidden_file" {
-						return false
-					}
-				}
-				return true
-			},
-		},
-		{
-			name:       "Dirs Only",
-			filter:     NewFilter("", false),
-			hideHidden: false,
-			dirsOnly:   true,
-			maxDepth:   0,
-			checkFunc: func(node *TreeNode) bool {
-				// Check if only directories are included
-				for _, child := range allChildrenRecursive(node) {
-					if !child.IsDir {
-						return false
-					}
-				}
-				return true
-			},
-		},
-		{
-			name:       "Max Depth Limit",
-			filter:     NewFilter("", false),
-			hideHidden: false,
-			dirsOnly:   false,
-			maxDepth:   1,
-			checkFunc: func(node *TreeNode) bool {
-				// Check if depth is limited
-				maxFoundDepth := 0
-				for _, n := range allChildrenRecursive(node) {
-					if n.Depth > maxFoundDepth {
-						maxFoundDepth = n.Depth
-					}
-				}
-				return maxFoundDepth <= 1 // depth should not exceed 1
-			},
-		},
-		{
-			name:       "Exclude Rules",
-			filter:     NewFilter(".log, build/", false),
-			hideHidden: false,
-			dirsOnly:   false,
-			maxDepth:   0,
-			checkFunc: func(node *TreeNode) bool {
-				// Check if exclude rules are effective
-				for _, child := range node.Children {
-					if child.Name == "log.log" || child.Name == "build" {
-						return false
-					}
-				}
-				return true
-			},
-		},
-		{
-			name:       "GitIgnore Integration",
-			filter:     NewFilter("", true),
-			hideHidden: false,
-			dirsOnly:   false,
-			maxDepth:   0,
-			checkFunc: func(node *TreeNode) bool {
-				// Check if .gitignore rules are effective
-				for _, child := range node.Children {
-					if child.Name == "log.log" || child.Name == "build" {
-						return false
-					}
-				}
-				return true
-			},
-		},
+		{"input order", treex.Options{}, "p/\n├── b\n├── a\n└── c/\n    ├── z\n    └── y\n"},
+		{"sorted", treex.Options{Sort: "name", Reverse: true}, "p/\n├── c/\n│   ├── z\n│   └── y\n├── b\n└── a\n"},
+		{"grouped", treex.Options{DirsFirst: true}, "p/\n├── c/\n│   ├── z\n│   └── y\n├── b\n└── a\n"},
+		{"limited", treex.Options{MaxEntries: 1}, "p/\n├── b\n└── … 2 more entries\n"},
 	}
-
 	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			node, err := getTreeNode(".", 1, testDir, tc.maxDepth, tc.filter, tc.hideHidden, tc.dirsOnly)
-			if err != nil {
-				t.Fatalf("%s: getTreeNode error: %v", tc.name, err)
-			}
-
-			if !tc.checkFunc(node) {
-				t.Errorf("%s: Check failed", tc.name)
-			}
-
-			// Test various output formats
-			_ = node.ToTreeString(true, "", false)
-			_ = node.ToIndentString(2, false)
-			_ = node.ToMarkdownString(0, false)
-			_ = node.ToMermaidString()
-		})
+		opts := &Options{Options: tc.opts, OutputFormat: "tree", InputFormat: "tree", InputFilePath: filePath, NoReport: true}
+		result, err := generate(opts)
+		if err != nil {
+			t.Fatalf("%s: generate error: %v", tc.name, err)
+		}
+		if result != tc.expected {
+			t.Errorf("%s: expected:\n%s\nBut got:\n%s", tc.name, tc.expected, result)
+		}
+	}
+
+	opts := &Options{Options: treex.Options{Sort: "bogus"}, OutputFormat: "tree", InputFormat: "tree", InputFilePath: filePath}
+	if _, err := generate(opts); err == nil {
+		t.Error("Should fail on an unknown sort order")
+	}
+}
+
+func TestPrintReadErrors(t *testing.T) {
+	tree, _ := treex.ParseListing("./\n├── a/\n└── b/\n", "tree")
+
+	var out strings.Builder
+	if printReadErrors(&out, tree) || out.Len() != 0 {
+		t.Errorf("Expected no errors, but got %q", out.String())
+	}
+
+	tree.Children[0].Err = errors.New("open a: permission denied")
+	tree.Children[1].Err = errors.New("open b: permission denied")
+	if !printReadErrors(&out, tree) {
+		t.Error("Expected read errors to be reported")
+	}
+	expected := "error: open a: permission denied\nerror: open b: permission denied\n2 directories could not be read\n"
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\nBut got:\n%s", expected, out.String())
 	}
 }
 
-// Get all child nodes recursively
-func allChildrenRecursive(node *TreeNode) []*TreeNode {
-	var result []*TreeNode
-	for _, child := range node.Children {
-		result = append(result, child)
-		result = append(result, allChildrenRecursive(child)...)
+func TestGetColorScheme(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "out.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	testCases := []struct {
+		mode    string
+		out     *os.File
+		noColor string
+		colored bool
+	}{
+		{"never", nil, "", false},
+		{"always", nil, "", true},
+		{"always", file, "1", true},
+		{"auto", nil, "", false},
+		{"auto", file, "", false},
+	}
+	for _, tc := range testCases {
+		t.Setenv("NO_COLOR", tc.noColor)
+		colors, err := getColorScheme(tc.mode, tc.out)
+		if err != nil || (colors != nil) != tc.colored {
+			t.Errorf("%s: expected colored=%v, but got %v (%v)", tc.mode, tc.colored, colors != nil, err)
+		}
+	}
+
+	if _, err := getColorScheme("sometimes", nil); err == nil {
+		t.Error("Expected an error for an invalid mode")
+	}
+
+	// The mode can be given as a separate argument
+	opts := &Options{}
+	fs := newFlagSet("treex", opts)
+	if err := fs.Parse([]string{"--color", "never", "-s"}); err != nil || opts.Color != "never" || fs.NArg() != 0 || !opts.ShowSizes {
+		t.Errorf("Expected --color never to set the mode, got %q with arguments %q (%v)", opts.Color, fs.Args(), err)
+	}
+
+	// Generated output, as used by --inject, is never colored
+	result, err := generate(&Options{Options: treex.Options{Dir: "."}, OutputFormat: "tree", Color: "always", NoReport: true})
+	if err != nil || strings.Contains(result, "\x1b") {
+		t.Errorf("Generated output shouldn't be colored (%v)", err)
 	}
-	return result
 }
@@ -0,0 +1,111 @@
+package treex
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+)
+
+// Report whether a path names an archive that can be browsed like a directory
+func isArchivePath(p string) bool {
+	return getArchiveType(p) != ""
+}
+
+// Get the archive type of a path from its extension: "zip", "tar", "tar.gz"
+// or "" for other files.
+func getArchiveType(p string) string {
+	lower := strings.ToLower(p)
+	switch {
+	case strings.HasSuffix(lower, ".zip"), strings.HasSuffix(lower, ".jar"):
+		return "zip"
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tar.gz"
+	case strings.HasSuffix(lower, ".tar"):
+		return "tar"
+	default:
+		return ""
+	}
+}
+
+// Read the entry list of a zip, tar or tar.gz archive
+func readArchiveEntries(archivePath string) ([]pathEntry, error) {
+	if getArchiveType(archivePath) == "zip" {
+		return readZipEntries(archivePath)
+	}
+
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if getArchiveType(archivePath) == "tar.gz" {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	return readTarEntries(r)
+}
+
+func readZipEntries(archivePath string) ([]pathEntry, error) {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	entries := make([]pathEntry, 0, len(r.File))
+	for _, f := range r.File {
+		info := f.FileInfo()
+		entry := pathEntry{
+			Path:    f.Name,
+			IsDir:   info.IsDir(),
+			Mode:    info.Mode(),
+			ModTime: info.ModTime(),
+			Info:    info,
+		}
+		if !entry.IsDir {
+			entry.Size = int64(f.UncompressedSize64)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func readTarEntries(r io.Reader) ([]pathEntry, error) {
+	var entries []pathEntry
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		info := header.FileInfo()
+		entry := pathEntry{
+			Path:    header.Name,
+			IsDir:   info.IsDir(),
+			Mode:    info.Mode(),
+			ModTime: info.ModTime(),
+			Info:    info,
+		}
+		if !entry.IsDir {
+			entry.Size = header.Size
+		}
+		if header.Typeflag == tar.TypeSymlink {
+			entry.LinkTarget = header.Linkname
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
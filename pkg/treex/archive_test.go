@@ -0,0 +1,147 @@
+package treex
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Archive entries matching createTestTree, with sizes
+var testArchiveEntries = []struct {
+	name    string
+	content string
+}{
+	{"root/", ""},
+	{"root/dir1/", ""},
+	{"root/dir1/file2.go", "package main"},
+	{"root/file1.txt", "hello"},
+}
+
+func writeTestZip(t *testing.T, path string) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for _, entry := range testArchiveEntries {
+		header := &zip.FileHeader{Name: entry.name}
+		if entry.content == "" {
+			header.SetMode(os.ModeDir | 0755)
+		} else {
+			header.SetMode(0640)
+		}
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			t.Fatalf("Failed to add archive entry: %v", err)
+		}
+		io.WriteString(w, entry.content)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+}
+
+func writeTestTar(t *testing.T, path string, compress bool) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+	defer f.Close()
+
+	var w io.Writer = f
+	if compress {
+		gz := gzip.NewWriter(f)
+		defer gz.Close()
+		w = gz
+	}
+
+	tw := tar.NewWriter(w)
+	for _, entry := range testArchiveEntries {
+		header := &tar.Header{Name: entry.name, Mode: 0640, Size: int64(len(entry.content)), Typeflag: tar.TypeReg}
+		if entry.content == "" {
+			header.Typeflag = tar.TypeDir
+			header.Mode = 0755
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("Failed to add archive entry: %v", err)
+		}
+		io.WriteString(tw, entry.content)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+}
+
+func TestGetArchiveType(t *testing.T) {
+	testCases := map[string]string{
+		"release.zip":     "zip",
+		"app.JAR":         "zip",
+		"image.tar":       "tar",
+		"image.tar.gz":    "tar.gz",
+		"image.tgz":       "tar.gz",
+		"notes.txt":       "",
+		"directory.d/zip": "",
+	}
+
+	for path, expected := range testCases {
+		if result := getArchiveType(path); result != expected {
+			t.Errorf("For %s, expected %q, but got %q", path, expected, result)
+		}
+	}
+}
+
+func TestReadArchiveEntries(t *testing.T) {
+	tempDir := t.TempDir()
+
+	zipPath := filepath.Join(tempDir, "test.zip")
+	writeTestZip(t, zipPath)
+	tarPath := filepath.Join(tempDir, "test.tar")
+	writeTestTar(t, tarPath, false)
+	tgzPath := filepath.Join(tempDir, "test.tar.gz")
+	writeTestTar(t, tgzPath, true)
+
+	for _, archivePath := range []string{zipPath, tarPath, tgzPath} {
+		entries, err := readArchiveEntries(archivePath)
+		if err != nil {
+			t.Errorf("%s: readArchiveEntries error: %v", archivePath, err)
+			continue
+		}
+
+		// The archive renders like the directory it was made from
+		root := buildTreeFromPaths(entries, "archive", &Options{}, NewFilter("", false))
+		if len(root.Children) != 1 || !equalTrees(root.Children[0], shiftDepth(createTestTree(), 1)) {
+			t.Errorf("%s: tree doesn't match, got:\n%s", archivePath, root.ToTreeString(true, "", false))
+			continue
+		}
+
+		file2 := root.Children[0].Children[0].Children[0]
+		if file2.Size != 12 || file2.Mode.Perm() != 0640 {
+			t.Errorf("%s: expected size 12 and mode 0640, but got %d and %v", archivePath, file2.Size, file2.Mode)
+		}
+		if !root.Children[0].Mode.IsDir() {
+			t.Errorf("%s: directories should keep their mode, got %v", archivePath, root.Children[0].Mode)
+		}
+		if root.Size != 17 {
+			t.Errorf("%s: expected total size 17, but got %d", archivePath, root.Size)
+		}
+	}
+
+	if _, err := readArchiveEntries(filepath.Join(tempDir, "missing.zip")); err == nil {
+		t.Error("Should fail on missing archives")
+	}
+}
+
+// Increase the depth of every node in a tree
+func shiftDepth(t *TreeNode, delta int) *TreeNode {
+	t.Depth += delta
+	for _, child := range t.Children {
+		shiftDepth(child, delta)
+	}
+	return t
+}
@@ -0,0 +1,167 @@
+package treex
+
+import (
+	"encoding/json"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Attach descriptions derived from the contents of each directory. Existing
+// descriptions (e.g. from a .treexdesc file) are kept. dirPath is the path of
+// t within fsys.
+func applyAutoDescriptions(t *TreeNode, fsys fs.FS, dirPath string) {
+	if !t.IsDir {
+		return
+	}
+
+	if t.Description == "" {
+		t.Description = getAutoDescription(fsys, dirPath)
+	}
+
+	for _, child := range t.Children {
+		applyAutoDescriptions(child, fsys, path.Join(dirPath, child.Name))
+	}
+}
+
+// Derive a one-line description for a directory, trying in order: the Go
+// package doc comment, the first line of the README, and the description
+// field of package.json, Cargo.toml or pyproject.toml.
+func getAutoDescription(fsys fs.FS, dirPath string) string {
+	sources := []func(fs.FS, string) string{
+		getGoPackageDescription,
+		getReadmeDescription,
+		getPackageJSONDescription,
+		getCargoDescription,
+		getPyprojectDescription,
+	}
+
+	for _, source := range sources {
+		if description := source(fsys, dirPath); description != "" {
+			return description
+		}
+	}
+	return ""
+}
+
+// Get the first sentence of the package doc comment, preferring doc.go
+func getGoPackageDescription(fsys fs.FS, dirPath string) string {
+	entries, err := fs.ReadDir(fsys, dirPath)
+	if err != nil {
+		return ""
+	}
+
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		files = append(files, name)
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i] == "doc.go" && files[j] != "doc.go"
+	})
+
+	fset := token.NewFileSet()
+	for _, name := range files {
+		src, err := fs.ReadFile(fsys, path.Join(dirPath, name))
+		if err != nil {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, src, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil || f.Doc == nil {
+			continue
+		}
+		if synopsis := new(doc.Package).Synopsis(f.Doc.Text()); synopsis != "" {
+			return synopsis
+		}
+	}
+	return ""
+}
+
+// Get the first heading, or failing that the first line of text, of the README
+func getReadmeDescription(fsys fs.FS, dirPath string) string {
+	entries, err := fs.ReadDir(fsys, dirPath)
+	if err != nil {
+		return ""
+	}
+
+	for _, entry := range entries {
+		name := strings.ToLower(entry.Name())
+		if entry.IsDir() || (name != "readme.md" && name != "readme") {
+			continue
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dirPath, entry.Name()))
+		if err != nil {
+			return ""
+		}
+
+		for _, line := range strings.Split(string(content), "\n") {
+			line = strings.TrimSpace(line)
+			// Skip blank lines, HTML comments/tags and badges
+			if line == "" || strings.HasPrefix(line, "<") || strings.HasPrefix(line, "[![") || strings.HasPrefix(line, "![") {
+				continue
+			}
+			return strings.TrimSpace(strings.TrimLeft(line, "#"))
+		}
+		return ""
+	}
+	return ""
+}
+
+func getPackageJSONDescription(fsys fs.FS, dirPath string) string {
+	content, err := fs.ReadFile(fsys, path.Join(dirPath, "package.json"))
+	if err != nil {
+		return ""
+	}
+
+	var manifest struct {
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(manifest.Description)
+}
+
+func getCargoDescription(fsys fs.FS, dirPath string) string {
+	return getTOMLDescription(fsys, path.Join(dirPath, "Cargo.toml"), "package")
+}
+
+func getPyprojectDescription(fsys fs.FS, dirPath string) string {
+	return getTOMLDescription(fsys, path.Join(dirPath, "pyproject.toml"), "project", "tool.poetry")
+}
+
+// Read a `description = "..."` key from one of the given TOML tables
+func getTOMLDescription(fsys fs.FS, filePath string, tables ...string) string {
+	content, err := fs.ReadFile(fsys, filePath)
+	if err != nil {
+		return ""
+	}
+
+	table := ""
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			table = strings.TrimSpace(strings.Trim(line, "[]"))
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found || strings.TrimSpace(key) != "description" {
+			continue
+		}
+		for _, t := range tables {
+			if t == table {
+				return unquoteYAMLValue(strings.TrimSpace(value))
+			}
+		}
+	}
+	return ""
+}
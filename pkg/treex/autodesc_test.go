@@ -0,0 +1,71 @@
+package treex
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestGetAutoDescription(t *testing.T) {
+	fsys := fstest.MapFS{}
+
+	files := map[string]string{
+		"gopkg/store.go":       "// Package store implements storage backends. It is fast.\npackage store\n",
+		"gopkg/store_test.go":  "// Package store tests.\npackage store\n",
+		"gopkg/README.md":      "# Not used\n",
+		"docs/README.md":       "[![badge](x)](y)\n\n## Project documentation\n\nMore text\n",
+		"web/package.json":     `{"name": "web", "description": "Frontend app"}`,
+		"crate/Cargo.toml":     "[package]\nname = \"crate\"\ndescription = \"A Rust crate\"\n",
+		"py/pyproject.toml":    "[build-system]\ndescription = \"wrong\"\n[project]\ndescription = 'Python tools'\n",
+		"plain/notes.txt":      "nothing to see",
+		"docgo/a.go":           "package docgo\n",
+		"docgo/doc.go":         "// Package docgo is documented in doc.go.\npackage docgo\n",
+		"docgo/z.go":           "// Package docgo is documented twice.\npackage docgo\n",
+		"textreadme/readme":    "\nPlain first line\n",
+		"badjson/package.json": "{",
+		"gopkg/internal/x.txt": "x",
+	}
+	for name, content := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(content)}
+	}
+
+	testCases := []struct {
+		dir      string
+		expected string
+	}{
+		{"gopkg", "Package store implements storage backends."},
+		{"docgo", "Package docgo is documented in doc.go."},
+		{"docs", "Project documentation"},
+		{"textreadme", "Plain first line"},
+		{"web", "Frontend app"},
+		{"crate", "A Rust crate"},
+		{"py", "Python tools"},
+		{"plain", ""},
+		{"badjson", ""},
+		{"missing", ""},
+	}
+
+	for _, tc := range testCases {
+		result := getAutoDescription(fsys, tc.dir)
+		if result != tc.expected {
+			t.Errorf("For %s, expected %q, but got %q", tc.dir, tc.expected, result)
+		}
+	}
+
+	// Manual descriptions take precedence and files are left alone
+	root := &TreeNode{Name: ".", IsDir: true}
+	root.Children = []*TreeNode{
+		{Name: "gopkg", IsDir: true, Depth: 1, Description: "Manual"},
+		{Name: "web", IsDir: true, Depth: 1},
+		{Name: "package.json", IsDir: false, Depth: 1},
+	}
+	applyAutoDescriptions(root, fsys, ".")
+	if root.Children[0].Description != "Manual" {
+		t.Error("Auto descriptions should not replace manual descriptions")
+	}
+	if root.Children[1].Description != "Frontend app" {
+		t.Errorf("Expected web description, but got %q", root.Children[1].Description)
+	}
+	if root.Children[2].Description != "" {
+		t.Error("Files should not get auto descriptions")
+	}
+}
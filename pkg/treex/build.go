@@ -0,0 +1,122 @@
+package treex
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// Options controls how Build assembles a tree
+type Options struct {
+	Dir          string   // directory or archive (zip, tar, tar.gz) to scan; defaults to "."
+	FS           fs.FS    // filesystem walked instead of Dir when set; the root is still named after Dir
+	Paths        []string // paths relative to the working directory to build the tree from instead of walking
+	MaxDepth     int      // maximum directory depth (0 for unlimited)
+	Exclude      string   // comma-separated exclude rules, e.g. "dir/, .txt"
+	HideHidden   bool
+	DirsOnly     bool
+	UseGitIgnore bool   // exclude the patterns of the .gitignore file in the working directory
+	Annotate     bool   // attach descriptions from the .treexdesc file of the scanned directory
+	DescFilePath string // description file to use instead of .treexdesc (implies Annotate)
+	AutoDesc     bool   // derive directory descriptions from package docs, READMEs and manifests
+	Jobs         int    // number of directories read concurrently; FS must be safe for concurrent use above 1
+	FollowLinks  bool   // descend into symbolic links to directories, skipping links back to a parent
+	Sort         string // order of children, one of SortOrders; defaults to "name"
+	Reverse      bool   // reverse the sort order
+	DirsFirst    bool   // list directories before files
+	FilesFirst   bool   // list files before directories
+	IgnoreCase   bool   // sort names case-insensitively
+	MaxEntries   int    // maximum number of entries shown per directory (0 for unlimited)
+	MaxTotal     int    // maximum number of entries shown in all (0 for unlimited)
+	GroupElided  bool   // describe the entries left out by the limits by extension
+	Compact      bool   // merge chains of single-child directories, see TreeNode.Compact
+	Metadata     bool   // record owners, groups, inodes and link counts
+}
+
+// Build assembles the tree described by opts, including descriptions
+func Build(opts *Options) (*TreeNode, error) {
+	dir := opts.Dir
+	if dir == "" {
+		dir = "."
+	}
+
+	// get the absolute path and ensure it ends with "/"
+	absolutePath, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(absolutePath, "/") {
+		absolutePath += "/"
+	}
+	rootName := getRelativePath(dir, absolutePath)
+
+	if err := validateSortOptions(opts); err != nil {
+		return nil, err
+	}
+
+	// filters
+	filter := NewFilter(opts.Exclude, opts.UseGitIgnore)
+
+	fsys := opts.FS
+	if fsys == nil {
+		fsys = newDirFS(dir)
+	}
+
+	var node *TreeNode
+	if opts.Paths != nil {
+		node = buildTreeFromPaths(getPathEntries(opts.Paths), ".", opts, filter)
+	} else if info, err := os.Stat(dir); opts.FS == nil && err == nil && !info.IsDir() && isArchivePath(dir) {
+		entries, err := readArchiveEntries(dir)
+		if err != nil {
+			return nil, fmt.Errorf("reading archive: %w", err)
+		}
+		node = buildTreeFromPaths(entries, rootName, opts, filter)
+	} else {
+		node, err = walkTree(fsys, rootName, opts, filter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// descriptions
+	if opts.Annotate || opts.DescFilePath != "" {
+		var descriptions map[string]string
+		if opts.DescFilePath != "" {
+			descriptions, err = loadDescriptions(opts.DescFilePath)
+		} else {
+			descriptions, err = loadDescriptionsFS(fsys, descriptionFileName)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading descriptions: %w", err)
+		}
+		applyDescriptions(node, descriptions, "")
+	}
+	if opts.AutoDesc {
+		applyAutoDescriptions(node, fsys, ".")
+	}
+
+	if err := node.Arrange(opts); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// Arrange sorts, compacts and limits the entries of t as described by the
+// Sort, Reverse, DirsFirst, FilesFirst, IgnoreCase, Compact, MaxEntries,
+// MaxTotal and GroupElided options. Build does this itself; use it on trees
+// parsed from listings.
+func (t *TreeNode) Arrange(opts *Options) error {
+	if err := validateSortOptions(opts); err != nil {
+		return err
+	}
+	sortTree(t, opts)
+
+	// Compacting and limiting come last so merged chains count as a single
+	// entry and keep their descriptions
+	if opts.Compact {
+		t.Compact()
+	}
+	limitEntries(t, opts)
+	return nil
+}
@@ -0,0 +1,157 @@
+package treex
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// ColorScheme colors entries with ANSI escape sequences the way `ls --color`
+// does, by type and extension. It reads the LS_COLORS format produced by
+// dircolors, plus the treex key "tc" for the connectors of the tree format.
+type ColorScheme struct {
+	types      map[string]string // SGR parameters by key, e.g. "di" -> "01;34"
+	extensions []colorPattern    // "*.ext" entries, later ones take precedence
+}
+
+type colorPattern struct {
+	suffix string // lowercase
+	sgr    string
+}
+
+// The colors of dircolors' default database for the keys treex uses
+var defaultTypeColors = map[string]string{
+	"di": "01;34", "ln": "01;36", "or": "40;31;01", "ex": "01;32",
+	"pi": "40;33", "so": "01;35", "bd": "40;33;01", "cd": "40;33;01",
+	"su": "37;41", "sg": "30;43", "tw": "30;42", "ow": "34;42", "st": "37;44",
+	"tc": "90",
+}
+
+// Extension colors used when none are configured: archives in red, images
+// and videos in magenta, audio in cyan
+var defaultExtensionColors = "*.tar=01;31:*.tgz=01;31:*.gz=01;31:*.zip=01;31:*.7z=01;31:*.rar=01;31:*.xz=01;31:*.bz2=01;31:*.zst=01;31:*.jar=01;31:*.deb=01;31:*.rpm=01;31:" +
+	"*.png=01;35:*.jpg=01;35:*.jpeg=01;35:*.gif=01;35:*.bmp=01;35:*.svg=01;35:*.webp=01;35:*.mp4=01;35:*.mkv=01;35:*.avi=01;35:*.mov=01;35:*.webm=01;35:" +
+	"*.mp3=00;36:*.wav=00;36:*.ogg=00;36:*.flac=00;36:*.m4a=00;36"
+
+// NewColorScheme parses colors in the LS_COLORS format, e.g.
+// "di=01;34:ln=01;36:*.go=36". Keys the value doesn't set keep the defaults
+// of dircolors, and default extension colors are used if it sets none.
+func NewColorScheme(lsColors string) *ColorScheme {
+	c := &ColorScheme{types: make(map[string]string)}
+	for key, sgr := range defaultTypeColors {
+		c.types[key] = sgr
+	}
+	c.parse(lsColors)
+	if len(c.extensions) == 0 {
+		c.parse(defaultExtensionColors)
+	}
+	return c
+}
+
+func (c *ColorScheme) parse(lsColors string) {
+	for _, entry := range strings.Split(lsColors, ":") {
+		key, sgr, ok := strings.Cut(entry, "=")
+		if !ok {
+			continue
+		}
+		if suffix, isPattern := strings.CutPrefix(key, "*"); isPattern {
+			c.extensions = append(c.extensions, colorPattern{strings.ToLower(suffix), sgr})
+		} else {
+			c.types[key] = sgr
+		}
+	}
+}
+
+// Get the SGR parameters of an entry, or "" to leave it uncolored
+func (c *ColorScheme) getEntryColor(t *TreeNode) string {
+	switch {
+	case t.Elided > 0:
+		return ""
+	case t.LinkBroken && c.types["or"] != "":
+		return c.types["or"]
+	case t.LinkTarget != "" && c.types["ln"] != "target":
+		return c.types["ln"]
+	}
+
+	// Links colored as "target" are colored like what they point to. Only
+	// whether it is a directory is known, not its mode.
+	name, mode := t.Name, t.Mode
+	if t.LinkTarget != "" {
+		name, mode = path.Base(t.LinkTarget), 0
+	}
+
+	switch {
+	case t.IsDir:
+		return c.getDirColor(mode)
+	case mode&fs.ModeNamedPipe != 0:
+		return c.types["pi"]
+	case mode&fs.ModeSocket != 0:
+		return c.types["so"]
+	case mode&fs.ModeCharDevice != 0:
+		return c.types["cd"]
+	case mode&fs.ModeDevice != 0:
+		return c.types["bd"]
+	case mode&fs.ModeSetuid != 0 && c.types["su"] != "":
+		return c.types["su"]
+	case mode&fs.ModeSetgid != 0 && c.types["sg"] != "":
+		return c.types["sg"]
+	case mode&0111 != 0 && c.types["ex"] != "":
+		return c.types["ex"]
+	}
+
+	lowerName := strings.ToLower(name)
+	for i := len(c.extensions) - 1; i >= 0; i-- {
+		if strings.HasSuffix(lowerName, c.extensions[i].suffix) {
+			return c.extensions[i].sgr
+		}
+	}
+	if sgr := c.types["fi"]; sgr != "" {
+		return sgr
+	}
+	return c.types["no"]
+}
+
+func (c *ColorScheme) getDirColor(mode fs.FileMode) string {
+	sticky, otherWritable := mode&fs.ModeSticky != 0, mode&0002 != 0
+	switch {
+	case sticky && otherWritable && c.types["tw"] != "":
+		return c.types["tw"]
+	case otherWritable && c.types["ow"] != "":
+		return c.types["ow"]
+	case sticky && c.types["st"] != "":
+		return c.types["st"]
+	}
+	return c.types["di"]
+}
+
+// Wrap s in the escape sequences of the given SGR parameters
+func paint(s, sgr string) string {
+	if s == "" || sgr == "" {
+		return s
+	}
+	return "\x1b[" + sgr + "m" + s + "\x1b[0m"
+}
+
+// Color a node name; a nil scheme leaves it as is
+func (c *ColorScheme) paintEntry(t *TreeNode, s string) string {
+	if c == nil {
+		return s
+	}
+	return paint(s, c.getEntryColor(t))
+}
+
+// Color the connectors and vertical lines of the tree format
+func (c *ColorScheme) paintConnector(s string) string {
+	if c == nil {
+		return s
+	}
+	return paint(s, c.types["tc"])
+}
+
+// Color the target of a broken link with the "mi" key, like ls
+func (c *ColorScheme) paintMissing(s string) string {
+	if c == nil {
+		return s
+	}
+	return paint(s, c.types["mi"])
+}
@@ -0,0 +1,73 @@
+package treex
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestEntryColors(t *testing.T) {
+	colors := NewColorScheme("di=01;34:ln=target:ex=01;32:*.go=36:*.gz=31:*.TAR.GZ=01;31:fi=00")
+
+	testCases := []struct {
+		node     *TreeNode
+		expected string
+	}{
+		{&TreeNode{Name: "cmd", IsDir: true, Mode: fs.ModeDir | 0755}, "01;34"},
+		{&TreeNode{Name: "tmp", IsDir: true, Mode: fs.ModeDir | fs.ModeSticky | 0777}, "30;42"},
+		{&TreeNode{Name: "main.go", Mode: 0644}, "36"},
+		{&TreeNode{Name: "release.tar.gz", Mode: 0644}, "01;31"},
+		{&TreeNode{Name: "notes.gz", Mode: 0644}, "31"},
+		{&TreeNode{Name: "build.sh", Mode: 0755}, "01;32"},
+		{&TreeNode{Name: "README", Mode: 0644}, "00"},
+		{&TreeNode{Name: "fifo", Mode: fs.ModeNamedPipe | 0644}, "40;33"},
+		{&TreeNode{Name: "lib", IsDir: true, Mode: fs.ModeSymlink | 0777, LinkTarget: "../lib"}, "01;34"},
+		{&TreeNode{Name: "latest", Mode: fs.ModeSymlink | 0777, LinkTarget: "v2/app.go"}, "36"},
+		{&TreeNode{Name: "gone", Mode: fs.ModeSymlink | 0777, LinkTarget: "nowhere", LinkBroken: true}, "40;31;01"},
+		{&TreeNode{Name: "… 3 .go", Elided: 3}, ""},
+	}
+	for _, tc := range testCases {
+		if result := colors.getEntryColor(tc.node); result != tc.expected {
+			t.Errorf("%s: expected %q, but got %q", tc.node.Name, tc.expected, result)
+		}
+	}
+
+	// Default extension colors only apply when none are configured
+	if result := NewColorScheme("").getEntryColor(&TreeNode{Name: "logo.PNG"}); result != "01;35" {
+		t.Errorf("Expected the default image color, but got %q", result)
+	}
+	if result := NewColorScheme("*.go=36").getEntryColor(&TreeNode{Name: "logo.png"}); result != "" {
+		t.Errorf("Expected no color, but got %q", result)
+	}
+}
+
+func TestRenderColors(t *testing.T) {
+	tree := &TreeNode{Name: "root", IsDir: true, Description: "Project", Children: []*TreeNode{
+		{Name: "cmd", IsDir: true, Depth: 1, Children: []*TreeNode{
+			{Name: "main.go", Depth: 2},
+		}},
+		{Name: "up", Depth: 1, LinkTarget: "nowhere", LinkBroken: true},
+	}}
+	opts := RenderOptions{Colors: NewColorScheme("di=34:or=31:mi=05:*.go=36:tc=2")}
+
+	renderer, _ := NewRenderer("tree", opts)
+	var result strings.Builder
+	renderer.Render(&result, tree)
+	expected := "\x1b[34mroot\x1b[0m/  # Project\n" +
+		"\x1b[2m├── \x1b[0m\x1b[34mcmd\x1b[0m/\n" +
+		"\x1b[2m│   └── \x1b[0m\x1b[36mmain.go\x1b[0m\n" +
+		"\x1b[2m└── \x1b[0m\x1b[31mup\x1b[0m -> \x1b[05mnowhere\x1b[0m [broken link]\n"
+	if result.String() != expected {
+		t.Errorf("Expected:\n%q\nBut got:\n%q", expected, result.String())
+	}
+
+	// Markdown stays plain text
+	for _, format := range []string{"md", "md-code", "md-table"} {
+		renderer, _ = NewRenderer(format, opts)
+		result.Reset()
+		renderer.Render(&result, tree)
+		if strings.Contains(result.String(), "\x1b") {
+			t.Errorf("%s output shouldn't be colored:\n%q", format, result.String())
+		}
+	}
+}
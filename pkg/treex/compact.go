@@ -0,0 +1,43 @@
+package treex
+
+// Compact merges chains of directories that each hold nothing but a single
+// directory into one node named after the whole chain, e.g.
+// "src/main/java/com/acme/app", like GitHub and IDEs do. The root itself is
+// never merged into its child.
+func (t *TreeNode) Compact() {
+	for i, child := range t.Children {
+		t.Children[i] = child.compactChain()
+	}
+	setDepth(t, t.Depth)
+}
+
+// Merge t with its single child directory as long as possible, then compact
+// the children of the result
+func (t *TreeNode) compactChain() *TreeNode {
+	for t.canMergeChild() {
+		child := t.Children[0]
+		merged := *child
+		merged.Name = t.Name + "/" + child.Name
+		merged.Size = t.Size
+		merged.Filtered += t.Filtered
+		if merged.Description == "" {
+			merged.Description = t.Description
+		}
+		t = &merged
+	}
+
+	for i, child := range t.Children {
+		t.Children[i] = child.compactChain()
+	}
+	return t
+}
+
+// Report whether t only holds a directory it can be merged with. Links are
+// kept apart so their targets stay readable.
+func (t *TreeNode) canMergeChild() bool {
+	if !t.IsDir || len(t.Children) != 1 || t.LinkTarget != "" || t.Err != nil || t.Recursive {
+		return false
+	}
+	child := t.Children[0]
+	return child.IsDir && child.LinkTarget == ""
+}
@@ -0,0 +1,60 @@
+package treex
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCompact(t *testing.T) {
+	fsys := fstest.MapFS{
+		"src/main/java/com/acme/app/App.java": {Data: make([]byte, 10)},
+		"src/test/AppTest.java":               {Data: make([]byte, 5)},
+		"docs/api/index.md":                   {Data: make([]byte, 3)},
+		"go.mod":                              {Data: make([]byte, 1)},
+		".treexdesc":                          {Data: []byte("src/main: Sources\n")},
+	}
+
+	node, err := Build(&Options{Dir: "root", FS: fsys, Compact: true, Annotate: true, HideHidden: true})
+	if err != nil {
+		t.Fatalf("Build error: %v", err)
+	}
+	expected := `root/
+├── docs/api/
+│   └── index.md
+├── go.mod
+└── src/
+    ├── main/java/com/acme/app/  # Sources
+    │   └── App.java
+    └── test/
+        └── AppTest.java
+`
+	if result := node.ToTreeString(true, "", false); result != expected {
+		t.Errorf("Expected:\n%s\nBut got:\n%s", expected, result)
+	}
+
+	merged := node.Children[2].Children[0]
+	if merged.Depth != 2 || merged.Children[0].Depth != 3 || merged.Size != 10 {
+		t.Errorf("Unexpected merged node %+v", merged)
+	}
+	if report := node.Report(); report.Directories != 9 || report.Files != 4 {
+		t.Errorf("Compacted chains should count all their directories, got %+v", report)
+	}
+
+	// The root, links and unreadable directories are never merged
+	tree := &TreeNode{Name: "root", IsDir: true, Children: []*TreeNode{
+		{Name: "lib", IsDir: true, Depth: 1, LinkTarget: "../lib", Children: []*TreeNode{
+			{Name: "sub", IsDir: true, Depth: 2},
+		}},
+	}}
+	tree.Children[0].Children[0].Children = []*TreeNode{{Name: "broken", IsDir: true, Depth: 3, Err: errors.New("denied"), Children: []*TreeNode{{Name: "x", IsDir: true, Depth: 4}}}}
+	tree.Compact()
+	expected = `root/
+└── lib -> ../lib/
+    └── sub/broken/ [error opening dir]
+        └── x/
+`
+	if result := tree.ToTreeString(true, "", false); result != expected {
+		t.Errorf("Expected:\n%s\nBut got:\n%s", expected, result)
+	}
+}
@@ -0,0 +1,173 @@
+package treex
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+)
+
+// Default name of the sidecar file mapping paths to descriptions
+const descriptionFileName = ".treexdesc"
+
+// Load descriptions from a sidecar file. The file is a flat YAML mapping of
+// paths (relative to the scanned directory) to text:
+//
+//	cmd/: CLI entry points
+//	"docs/img": Screenshots used by the README
+func loadDescriptions(filePath string) (map[string]string, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return parseDescriptions(string(content))
+}
+
+// Load descriptions from a sidecar file within fsys
+func loadDescriptionsFS(fsys fs.FS, name string) (map[string]string, error) {
+	content, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return parseDescriptions(string(content))
+}
+
+func parseDescriptions(content string) (map[string]string, error) {
+	descriptions := make(map[string]string)
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		// Skip empty lines, comments and document markers
+		if line == "" || strings.HasPrefix(line, "#") || line == "---" {
+			continue
+		}
+
+		key, value, err := splitYAMLPair(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", i+1, err)
+		}
+		descriptions[normalizeDescriptionPath(key)] = value
+	}
+
+	return descriptions, nil
+}
+
+// Split a `key: value` line, honoring quoted keys and values
+func splitYAMLPair(line string) (string, string, error) {
+	var key, rest string
+	if line[0] == '"' || line[0] == '\'' {
+		end := strings.IndexByte(line[1:], line[0])
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated quoted key")
+		}
+		key = line[1 : end+1]
+		rest = strings.TrimSpace(line[end+2:])
+		if !strings.HasPrefix(rest, ":") {
+			return "", "", fmt.Errorf("expected ':' after key")
+		}
+		rest = rest[1:]
+	} else {
+		sep := strings.Index(line, ": ")
+		if sep < 0 {
+			if !strings.HasSuffix(line, ":") {
+				return "", "", fmt.Errorf("expected 'path: description'")
+			}
+			sep = len(line) - 1
+		}
+		key = strings.TrimSpace(line[:sep])
+		rest = line[sep+1:]
+	}
+
+	return key, unquoteYAMLValue(strings.TrimSpace(rest)), nil
+}
+
+func unquoteYAMLValue(value string) string {
+	if len(value) >= 2 {
+		if (value[0] == '"' && value[len(value)-1] == '"') || (value[0] == '\'' && value[len(value)-1] == '\'') {
+			return value[1 : len(value)-1]
+		}
+	}
+
+	// Strip trailing comments from plain values
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return value
+}
+
+// Normalize a path so "./cmd/", "cmd/" and "cmd" all refer to the same entry
+func normalizeDescriptionPath(p string) string {
+	p = path.Clean(strings.TrimSuffix(p, "/"))
+	if p == "." || p == "/" {
+		return ""
+	}
+	return strings.TrimPrefix(p, "./")
+}
+
+// Attach descriptions to the nodes of a tree. nodePath is the path of t
+// relative to the scanned directory ("" for the root).
+func applyDescriptions(t *TreeNode, descriptions map[string]string, nodePath string) {
+	if description, ok := descriptions[nodePath]; ok {
+		t.Description = description
+	}
+
+	for _, child := range t.Children {
+		applyDescriptions(child, descriptions, path.Join(nodePath, child.Name))
+	}
+}
+
+// Get the column at which trailing description comments start, i.e. the width
+// of the widest described line. indentWidth is the width added per depth level.
+func (t *TreeNode) getDescriptionColumn(indentWidth int, opts RenderOptions) int {
+	column := 0
+	if t.Description != "" {
+		column = t.Depth*indentWidth + displayWidth(t.getEntryString(opts))
+	}
+
+	for _, child := range t.Children {
+		if c := child.getDescriptionColumn(indentWidth, opts); c > column {
+			column = c
+		}
+	}
+	return column
+}
+
+// Get the trailing comment for a line of the given width
+func (t *TreeNode) getDescriptionComment(lineWidth int, column int) string {
+	if t.Description == "" {
+		return ""
+	}
+
+	padding := column - lineWidth
+	if padding < 0 {
+		padding = 0
+	}
+	return strings.Repeat(" ", padding) + "  # " + t.Description
+}
+
+// Approximate the number of terminal columns a string occupies, ignoring
+// ANSI color sequences
+func displayWidth(s string) int {
+	width := 0
+	inEscape := false
+	for _, r := range s {
+		switch {
+		case inEscape:
+			inEscape = r != 'm'
+		case r == '\x1b':
+			inEscape = true
+		case r == 0xFE0F || r == 0x200D:
+			// Variation selectors and joiners take no space
+		case r >= 0x1100 && (r <= 0x115F || (r >= 0x2600 && r <= 0x27BF) || (r >= 0x2E80 && r <= 0xA4CF) ||
+			(r >= 0xAC00 && r <= 0xD7A3) || (r >= 0xF900 && r <= 0xFAFF) || (r >= 0xFF00 && r <= 0xFF60) ||
+			(r >= 0x1F300 && r <= 0x1FAFF)):
+			// Wide CJK characters and emoji
+			width += 2
+		default:
+			width++
+		}
+	}
+	return width
+}
@@ -0,0 +1,117 @@
+package treex
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseDescriptions(t *testing.T) {
+	content := `# Project layout
+---
+./: Project root
+dir1/: First directory
+"dir1/file2.go": "Go source: main logic"
+'file1.txt': Plain text # trailing comment
+empty:
+`
+
+	descriptions, err := parseDescriptions(content)
+	if err != nil {
+		t.Fatalf("parseDescriptions error: %v", err)
+	}
+
+	expected := map[string]string{
+		"":              "Project root",
+		"dir1":          "First directory",
+		"dir1/file2.go": "Go source: main logic",
+		"file1.txt":     "Plain text",
+		"empty":         "",
+	}
+
+	if len(descriptions) != len(expected) {
+		t.Errorf("Expected %d descriptions, but got %d: %v", len(expected), len(descriptions), descriptions)
+	}
+	for key, value := range expected {
+		if descriptions[key] != value {
+			t.Errorf("For %q, expected %q, but got %q", key, value, descriptions[key])
+		}
+	}
+
+	// Test invalid lines
+	if _, err := parseDescriptions("no separator here"); err == nil {
+		t.Error("Should fail on lines without a key")
+	}
+	if _, err := parseDescriptions("\"unterminated: text"); err == nil {
+		t.Error("Should fail on unterminated quoted keys")
+	}
+}
+
+func TestDescriptionOutput(t *testing.T) {
+	tree := createTestTree()
+	applyDescriptions(tree, map[string]string{
+		"dir1":          "First directory",
+		"dir1/file2.go": "Go source",
+	}, "")
+
+	if tree.Children[0].Description != "First directory" || tree.Children[0].Children[0].Description != "Go source" {
+		t.Fatal("Descriptions should be attached to matching nodes")
+	}
+	if tree.Description != "" || tree.Children[1].Description != "" {
+		t.Error("Nodes without descriptions should stay empty")
+	}
+
+	// Comments are aligned after the widest described line
+	result := tree.ToTreeString(true, "", false)
+	expectedLines := []string{
+		"root/",
+		"├── dir1/         # First directory",
+		"│   └── file2.go  # Go source",
+		"└── file1.txt",
+	}
+	lines := strings.Split(strings.TrimSuffix(result, "\n"), "\n")
+	for i, line := range expectedLines {
+		if lines[i] != line {
+			t.Errorf("Tree line %d: expected %q, but got %q", i, line, lines[i])
+		}
+	}
+
+	result = tree.ToIndentString(2, false)
+	if !strings.Contains(result, "  dir1/       # First directory\n") || !strings.Contains(result, "    file2.go  # Go source\n") {
+		t.Errorf("Indent output should contain aligned descriptions:\n%s", result)
+	}
+
+	result = tree.ToMarkdownString(0, false)
+	if !strings.Contains(result, "  - dir1/ — First directory\n") {
+		t.Errorf("Markdown output should contain descriptions:\n%s", result)
+	}
+
+	result = tree.ToMarkdownTableString(false)
+	if !strings.Contains(result, "| `root/dir1/` | directory | 0B | First directory |\n") {
+		t.Errorf("Markdown table should contain descriptions:\n%s", result)
+	}
+
+	result = tree.ToMermaidString()
+	if !strings.Contains(result, "N2[\"dir1/<br/>First directory\"]") {
+		t.Errorf("Mermaid output should contain description labels:\n%s", result)
+	}
+}
+
+func TestDisplayWidth(t *testing.T) {
+	testCases := []struct {
+		s        string
+		expected int
+	}{
+		{"abc", 3},
+		{"├── ", 4},
+		{"📁 dir/", 7},
+		{"⚙️ a.yml", 8},
+		{"目录", 4},
+		{"\x1b[90m├── \x1b[0m\x1b[01;34mdir\x1b[0m/", 8},
+	}
+
+	for _, tc := range testCases {
+		if result := displayWidth(tc.s); result != tc.expected {
+			t.Errorf("For %q, expected %d, but got %d", tc.s, tc.expected, result)
+		}
+	}
+}
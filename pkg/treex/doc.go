@@ -0,0 +1,4 @@
+// Package treex builds trees of directories, archives, path lists and saved
+// listings, and renders them as text, Markdown, Mermaid, JSON, SVG or custom
+// templates. It is the library behind the treex command.
+package treex
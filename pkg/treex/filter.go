@@ -1,4 +1,4 @@
-package main
+package treex
 
 import (
 	"os"
@@ -69,7 +69,9 @@ func (f *Filter) loadGitIgnorePatterns() {
 	}
 }
 
-func (f *Filter) shouldExclude(name string, isDir bool, path string) bool {
+// ShouldExclude reports whether an entry is excluded, given its name and its
+// slash-separated path relative to the working directory
+func (f *Filter) ShouldExclude(name string, isDir bool, path string) bool {
 	if isDir {
 		for _, dir := range f.dirNames {
 			if matchPattern(name, dir) {
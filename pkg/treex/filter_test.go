@@ -1,7 +1,8 @@
-package main
+package treex
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -28,34 +29,34 @@ func TestNewFilter(t *testing.T) {
 func TestShouldExclude(t *testing.T) {
 	// Test directory filtering
 	f := NewFilter("node_modules/, .git/", false)
-	if !f.shouldExclude("node_modules", true, "node_modules") {
+	if !f.ShouldExclude("node_modules", true, "node_modules") {
 		t.Error("Should exclude node_modules directory")
 	}
-	if f.shouldExclude("src", true, "src") {
+	if f.ShouldExclude("src", true, "src") {
 		t.Error("Should not exclude src directory")
 	}
 
 	// Test suffix filtering
 	f = NewFilter(".txt, .log", false)
-	if !f.shouldExclude("file.txt", false, "file.txt") {
+	if !f.ShouldExclude("file.txt", false, "file.txt") {
 		t.Error("Should exclude .txt files")
 	}
-	if !f.shouldExclude("file.log", false, "file.log") {
+	if !f.ShouldExclude("file.log", false, "file.log") {
 		t.Error("Should exclude .log files")
 	}
-	if f.shouldExclude("file.go", false, "file.go") {
+	if f.ShouldExclude("file.go", false, "file.go") {
 		t.Error("Should not exclude .go files")
 	}
 
 	// Test pattern matching
 	f = NewFilter("test*, *temp", false)
-	if !f.shouldExclude("test_file", false, "test_file") {
+	if !f.ShouldExclude("test_file", false, "test_file") {
 		t.Error("Should exclude files starting with test")
 	}
-	if !f.shouldExclude("file_temp", false, "file_temp") {
+	if !f.ShouldExclude("file_temp", false, "file_temp") {
 		t.Error("Should exclude files ending with temp")
 	}
-	if f.shouldExclude("source.go", false, "source.go") {
+	if f.ShouldExclude("source.go", false, "source.go") {
 		t.Error("Should not exclude normal go files")
 	}
 }
@@ -90,13 +91,42 @@ func TestGitIgnoreIntegration(t *testing.T) {
 	}
 
 	// Test matching
-	if !f.shouldExclude("server.log", false, "server.log") {
+	if !f.ShouldExclude("server.log", false, "server.log") {
 		t.Error("Should exclude .log files")
 	}
-	if !f.shouldExclude("build", true, "build") {
+	if !f.ShouldExclude("build", true, "build") {
 		t.Error("Should exclude build directory")
 	}
-	if !f.shouldExclude("temp_file", false, "temp_file") {
+	if !f.ShouldExclude("temp_file", false, "temp_file") {
 		t.Error("Should exclude files starting with temp")
 	}
 }
+
+func TestExcludePathsInSubdirectory(t *testing.T) {
+	testDir := t.TempDir()
+	os.MkdirAll(filepath.Join(testDir, "sub", "skip"), 0755)
+	os.MkdirAll(filepath.Join(testDir, "sub", "keep"), 0755)
+	os.WriteFile(filepath.Join(testDir, "sub", "keep", "a.txt"), []byte("a"), 0644)
+	os.WriteFile(filepath.Join(testDir, ".gitignore"), []byte("sub/keep/a.txt\n"), 0644)
+
+	oldWd, _ := os.Getwd()
+	os.Chdir(testDir)
+	defer os.Chdir(oldWd)
+
+	// Paths are relative to the working directory, like -d sub -e sub/skip
+	for _, dir := range []string{"sub", "./sub", "sub/"} {
+		node, err := Build(&Options{Dir: dir, Exclude: "sub/skip", UseGitIgnore: true})
+		if err != nil {
+			t.Fatalf("Build error: %v", err)
+		}
+		if len(node.Children) != 1 || node.Children[0].Name != "keep" || len(node.Children[0].Children) != 0 {
+			t.Errorf("-d %s: expected sub/skip and sub/keep/a.txt to be excluded, got:\n%s", dir, node.ToTreeString(true, "", false))
+		}
+	}
+
+	// When scanning the working directory, paths have no prefix
+	node, _ := Build(&Options{Dir: ".", Exclude: "sub/skip"})
+	if sub := node.Children[1]; len(sub.Children) != 1 || sub.Children[0].Name != "keep" {
+		t.Errorf("Expected sub/skip to be excluded, got:\n%s", node.ToTreeString(true, "", false))
+	}
+}
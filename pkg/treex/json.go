@@ -0,0 +1,196 @@
+package treex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// jsonNode is the JSON representation of a TreeNode
+type jsonNode struct {
+	Name        string      `json:"name"`
+	Type        string      `json:"type"` // "directory", "file" or "elided"
+	Size        int64       `json:"size"`
+	Mode        string      `json:"mode,omitempty"` // as printed by ls, e.g. "-rw-r--r--"
+	Owner       string      `json:"owner,omitempty"`
+	Group       string      `json:"group,omitempty"`
+	Inode       uint64      `json:"inode,omitempty"`
+	HardLinks   uint64      `json:"links,omitempty"` // number of hard links
+	Description string      `json:"description,omitempty"`
+	Error       string      `json:"error,omitempty"`     // why the directory couldn't be read
+	Target      string      `json:"target,omitempty"`    // target of a symbolic link
+	Broken      bool        `json:"broken,omitempty"`    // the link's target doesn't exist
+	Recursive   bool        `json:"recursive,omitempty"` // a followed link to a parent directory
+	Elided      int         `json:"elided,omitempty"`    // number of entries left out by the limits
+	Children    []*jsonNode `json:"children,omitempty"`
+	Report      *Report     `json:"report,omitempty"` // only in the root
+}
+
+// Get the JSON representation of a node, without its children
+func (t *TreeNode) toJSONNode() *jsonNode {
+	node := &jsonNode{
+		Name:        t.Name,
+		Type:        "file",
+		Size:        t.Size,
+		Description: t.Description,
+		Owner:       t.Owner,
+		Group:       t.Group,
+		Inode:       t.Inode,
+		HardLinks:   t.HardLinks,
+	}
+	if t.IsDir {
+		node.Type = "directory"
+	} else if t.Elided > 0 {
+		node.Type = "elided"
+		node.Elided = t.Elided
+	}
+	if t.Mode != 0 {
+		node.Mode = t.Mode.String()
+	}
+	if t.Err != nil {
+		node.Error = t.Err.Error()
+	}
+	node.Target, node.Broken, node.Recursive = t.LinkTarget, t.LinkBroken, t.Recursive
+	return node
+}
+
+func (n *jsonNode) toTreeNode(depth int) (*TreeNode, error) {
+	if n.Name == "" {
+		return nil, fmt.Errorf("entry without a name")
+	}
+
+	node := &TreeNode{
+		Name:        n.Name,
+		Size:        n.Size,
+		Description: n.Description,
+		Depth:       depth,
+		Owner:       n.Owner,
+		Group:       n.Group,
+		Inode:       n.Inode,
+		HardLinks:   n.HardLinks,
+		LinkTarget:  n.Target,
+		LinkBroken:  n.Broken,
+		Recursive:   n.Recursive,
+	}
+	if n.Error != "" {
+		node.Err = errors.New(n.Error)
+	}
+	switch n.Type {
+	case "directory":
+		node.IsDir = true
+	case "file", "":
+		node.IsDir = len(n.Children) > 0
+	case "elided":
+		node.Elided = n.Elided
+	default:
+		return nil, fmt.Errorf("%s: unknown type %q", n.Name, n.Type)
+	}
+
+	if n.Mode != "" {
+		mode, err := parseFileMode(n.Mode)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", n.Name, err)
+		}
+		node.Mode = mode
+	}
+
+	for _, child := range n.Children {
+		childNode, err := child.toTreeNode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, childNode)
+	}
+	return node, nil
+}
+
+// ToJSONString renders the tree as an indented JSON document
+func (t *TreeNode) ToJSONString() string {
+	return renderString(func(w io.Writer) {
+		t.writeJSON(w, "", nil)
+		io.WriteString(w, "\n")
+	})
+}
+
+// Write the node as json.MarshalIndent would with the given prefix, one node
+// at a time instead of building the whole document in memory. The report is
+// added after the children if not nil.
+func (t *TreeNode) writeJSON(w io.Writer, prefix string, report *Report) {
+	data, err := json.MarshalIndent(t.toJSONNode(), prefix, "  ")
+	if err != nil {
+		// Marshaling plain strings and numbers can't fail
+		panic(err)
+	}
+	if len(t.Children) == 0 && report == nil {
+		w.Write(data)
+		return
+	}
+
+	// Reopen the object to append the children and the report
+	data = data[:len(data)-len("\n"+prefix+"}")]
+	w.Write(data)
+	if len(t.Children) > 0 {
+		io.WriteString(w, ",\n"+prefix+"  \"children\": [\n")
+		for i, child := range t.Children {
+			io.WriteString(w, prefix+"    ")
+			child.writeJSON(w, prefix+"    ", nil)
+			if i < len(t.Children)-1 {
+				io.WriteString(w, ",")
+			}
+			io.WriteString(w, "\n")
+		}
+		io.WriteString(w, prefix+"  ]")
+	}
+	if report != nil {
+		data, _ := json.MarshalIndent(report, prefix+"  ", "  ")
+		io.WriteString(w, ",\n"+prefix+"  \"report\": "+string(data))
+	}
+	io.WriteString(w, "\n"+prefix+"}")
+}
+
+// Parse a JSON document produced by the json format back into a tree
+func parseJSONListing(text string) (*TreeNode, error) {
+	var root jsonNode
+	if err := json.Unmarshal([]byte(text), &root); err != nil {
+		return nil, err
+	}
+	return root.toTreeNode(0)
+}
+
+// Parse a mode string as printed by fs.FileMode.String, e.g. "drwxr-xr-x"
+func parseFileMode(s string) (fs.FileMode, error) {
+	if len(s) < 10 {
+		return 0, fmt.Errorf("invalid mode %q", s)
+	}
+
+	var mode fs.FileMode
+	typeChars, perms := s[:len(s)-9], s[len(s)-9:]
+
+	// Type and special bits use the same letters as fs.FileMode.String
+	const modeChars = "dalTLDpSugct?"
+	for _, c := range typeChars {
+		if c == '-' {
+			continue
+		}
+		i := strings.IndexRune(modeChars, c)
+		if i < 0 {
+			return 0, fmt.Errorf("invalid mode %q", s)
+		}
+		mode |= 1 << uint(31-i)
+	}
+
+	const permChars = "rwxrwxrwx"
+	for i, c := range perms {
+		switch c {
+		case rune(permChars[i]):
+			mode |= 1 << uint(8-i)
+		case '-':
+		default:
+			return 0, fmt.Errorf("invalid mode %q", s)
+		}
+	}
+	return mode, nil
+}
@@ -0,0 +1,121 @@
+package treex
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestToJSONString(t *testing.T) {
+	tree := createTestTree()
+	tree.Children[1].Size = 42
+	tree.Children[1].Description = "Notes"
+
+	result := tree.ToJSONString()
+	expectedPatterns := []string{
+		`"name": "root"`,
+		`"type": "directory"`,
+		`"name": "file1.txt"`,
+		`"type": "file"`,
+		`"size": 42`,
+		`"description": "Notes"`,
+	}
+
+	for _, pattern := range expectedPatterns {
+		if !strings.Contains(result, pattern) {
+			t.Errorf("JSON output missing expected pattern: %s\n%s", pattern, result)
+		}
+	}
+
+	// Round trip
+	parsed, err := parseJSONListing(result)
+	if err != nil {
+		t.Fatalf("parseJSONListing error: %v", err)
+	}
+	if !equalTrees(parsed, tree) || parsed.Children[1].Size != 42 {
+		t.Errorf("Parsed tree doesn't match, got:\n%s", parsed.ToJSONString())
+	}
+}
+
+func TestWriteJSONMatchesMarshalIndent(t *testing.T) {
+	tree := createTestTree()
+	tree.Children[0].Description = `<quoted "dir">`
+
+	// Build the whole document in memory the straightforward way
+	var toFullJSONNode func(t *TreeNode) *jsonNode
+	toFullJSONNode = func(t *TreeNode) *jsonNode {
+		node := t.toJSONNode()
+		for _, child := range t.Children {
+			node.Children = append(node.Children, toFullJSONNode(child))
+		}
+		return node
+	}
+	expected, _ := json.MarshalIndent(toFullJSONNode(tree), "", "  ")
+
+	if result := tree.ToJSONString(); result != string(expected)+"\n" {
+		t.Errorf("Expected:\n%s\nBut got:\n%s", expected, result)
+	}
+}
+
+func TestParseJSONListingErrors(t *testing.T) {
+	testCases := []string{
+		`{`,
+		`{"type": "directory"}`,
+		`{"name": "a", "type": "socket"}`,
+		`{"name": "a", "type": "directory", "children": [{"name": ""}]}`,
+	}
+
+	for _, tc := range testCases {
+		if _, err := parseJSONListing(tc); err == nil {
+			t.Errorf("Should fail to parse %s", tc)
+		}
+	}
+}
+
+func TestParseListingFormats(t *testing.T) {
+	tree := createTestTree()
+
+	inputs := map[string]string{
+		"tree":   tree.ToTreeString(true, "", false),
+		"indent": tree.ToIndentString(4, false),
+		"md":     tree.ToMarkdownString(0, false),
+		"json":   tree.ToJSONString(),
+	}
+
+	for format, content := range inputs {
+		parsed, err := ParseListing(content, format)
+		if err != nil {
+			t.Errorf("%s: ParseListing error: %v", format, err)
+			continue
+		}
+		if !equalTrees(parsed, tree) {
+			t.Errorf("%s: parsed tree doesn't match, got:\n%s", format, parsed.ToTreeString(true, "", false))
+		}
+	}
+
+	if _, err := ParseListing(inputs["tree"], "xml"); err == nil {
+		t.Error("Should fail on unknown input formats")
+	}
+}
+
+func TestParseFileMode(t *testing.T) {
+	modes := []os.FileMode{0644, os.ModeDir | 0755, os.ModeSymlink | 0777, os.ModeSetuid | 0755, 0}
+
+	for _, mode := range modes {
+		parsed, err := parseFileMode(mode.String())
+		if err != nil {
+			t.Errorf("For %s, unexpected error: %v", mode, err)
+			continue
+		}
+		if parsed != mode {
+			t.Errorf("For %s, got %s", mode, parsed)
+		}
+	}
+
+	for _, invalid := range []string{"", "rwx", "-rwxrwxrwz", "Xrwxrwxrwx"} {
+		if _, err := parseFileMode(invalid); err == nil {
+			t.Errorf("Should fail to parse %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package treex
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LayoutSpec declares the expected structure of a directory. Patterns are
+// slash-separated paths relative to the scanned directory and may use the
+// globs supported by path.Match plus "**" for any number of directories. A
+// trailing "/" only matches directories.
+type LayoutSpec struct {
+	Required  []string `json:"required"`
+	Forbidden []string `json:"forbidden"`
+	Optional  []string `json:"optional"`
+	Strict    bool     `json:"strict"` // report entries not covered by required or optional patterns
+}
+
+// LayoutViolation is a difference between a LayoutSpec and an actual tree
+type LayoutViolation struct {
+	Kind    string // "missing", "forbidden" or "unexpected"
+	Pattern string
+	Path    string
+}
+
+func (v LayoutViolation) String() string {
+	switch v.Kind {
+	case "missing":
+		return fmt.Sprintf("missing: %s", v.Pattern)
+	case "forbidden":
+		return fmt.Sprintf("forbidden: %s (matches %s)", v.Path, v.Pattern)
+	default:
+		return fmt.Sprintf("unexpected: %s", v.Path)
+	}
+}
+
+// LoadLayoutSpec loads a spec file. JSON and YAML files are detected by
+// extension; anything else is read as a tree listing.
+func LoadLayoutSpec(filePath string) (*LayoutSpec, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".json":
+		spec := &LayoutSpec{}
+		if err := json.Unmarshal(content, spec); err != nil {
+			return nil, err
+		}
+		return spec, nil
+	case ".yaml", ".yml":
+		return parseLayoutYAML(string(content))
+	default:
+		return parseLayoutListing(string(content))
+	}
+}
+
+// Parse a YAML spec with required/forbidden/optional lists and a strict flag
+func parseLayoutYAML(content string) (*LayoutSpec, error) {
+	spec := &LayoutSpec{}
+	var current *[]string
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			continue
+		}
+
+		// List item of the current key
+		if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			if current == nil {
+				return nil, fmt.Errorf("line %d: list item outside of a list", i+1)
+			}
+			*current = append(*current, unquoteYAMLValue(strings.TrimSpace(strings.TrimPrefix(trimmed, "-"))))
+			continue
+		}
+
+		key, value, err := splitYAMLPair(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", i+1, err)
+		}
+
+		switch key {
+		case "required":
+			current = &spec.Required
+		case "forbidden":
+			current = &spec.Forbidden
+		case "optional":
+			current = &spec.Optional
+		case "strict":
+			spec.Strict = value == "true"
+			current = nil
+			continue
+		default:
+			return nil, fmt.Errorf("line %d: unknown key %q", i+1, key)
+		}
+
+		// Inline list: key: [a, b]
+		if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+			for _, item := range strings.Split(strings.Trim(value, "[]"), ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					*current = append(*current, unquoteYAMLValue(item))
+				}
+			}
+		} else if value != "" {
+			return nil, fmt.Errorf("line %d: expected a list for %q", i+1, key)
+		}
+	}
+
+	return spec, nil
+}
+
+// Parse a tree listing spec. Every entry is required unless its comment is
+// "# optional" or "# forbidden". Listing specs are strict: entries that
+// aren't listed are unexpected, except inside directories listed without
+// contents.
+func parseLayoutListing(content string) (*LayoutSpec, error) {
+	root, err := parseTreeListing(content)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &LayoutSpec{Strict: true}
+	var collect func(node *TreeNode, nodePath string)
+	collect = func(node *TreeNode, nodePath string) {
+		pattern := nodePath
+		if node.IsDir {
+			pattern += "/"
+		}
+
+		switch strings.ToLower(node.Description) {
+		case "optional":
+			spec.Optional = append(spec.Optional, pattern)
+		case "forbidden":
+			spec.Forbidden = append(spec.Forbidden, pattern)
+		default:
+			spec.Required = append(spec.Required, pattern)
+		}
+
+		for _, child := range node.Children {
+			collect(child, path.Join(nodePath, child.Name))
+		}
+	}
+	for _, child := range root.Children {
+		collect(child, child.Name)
+	}
+
+	return spec, nil
+}
+
+// layoutEntry is an actual path in the tree being checked
+type layoutEntry struct {
+	path  string
+	isDir bool
+}
+
+// CheckLayout compares a tree against a spec and returns the violations, in
+// spec order followed by unexpected entries in tree order.
+func CheckLayout(root *TreeNode, spec *LayoutSpec) []LayoutViolation {
+	var entries []layoutEntry
+	var collect func(node *TreeNode, nodePath string)
+	collect = func(node *TreeNode, nodePath string) {
+		entries = append(entries, layoutEntry{nodePath, node.IsDir})
+		for _, child := range node.Children {
+			collect(child, path.Join(nodePath, child.Name))
+		}
+	}
+	for _, child := range root.Children {
+		collect(child, child.Name)
+	}
+
+	var violations []LayoutViolation
+	for _, pattern := range spec.Required {
+		found := false
+		for _, entry := range entries {
+			if matchLayoutPattern(pattern, entry) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			violations = append(violations, LayoutViolation{Kind: "missing", Pattern: pattern})
+		}
+	}
+
+	for _, pattern := range spec.Forbidden {
+		for _, entry := range entries {
+			if matchLayoutPattern(pattern, entry) {
+				violations = append(violations, LayoutViolation{Kind: "forbidden", Pattern: pattern, Path: entry.path})
+			}
+		}
+	}
+
+	if spec.Strict {
+		allowed := append(append([]string{}, spec.Required...), spec.Optional...)
+		// Parent directories of declared patterns are allowed as well
+		for _, pattern := range allowed {
+			segments := strings.Split(strings.TrimSuffix(pattern, "/"), "/")
+			for i := 1; i < len(segments); i++ {
+				allowed = append(allowed, strings.Join(segments[:i], "/")+"/")
+			}
+		}
+
+		for _, entry := range entries {
+			if !isLayoutEntryAllowed(entry, allowed, spec.Forbidden) {
+				violations = append(violations, LayoutViolation{Kind: "unexpected", Path: entry.path})
+			}
+		}
+	}
+
+	return violations
+}
+
+// An entry is allowed if it matches an allowed pattern, or if it's inside a
+// directory matched by a pattern without declared contents. Forbidden entries
+// are already reported and aren't reported again.
+func isLayoutEntryAllowed(entry layoutEntry, allowed []string, forbidden []string) bool {
+	for _, pattern := range forbidden {
+		if matchLayoutPattern(pattern, entry) {
+			return true
+		}
+	}
+
+	for _, pattern := range allowed {
+		if matchLayoutPattern(pattern, entry) {
+			return true
+		}
+	}
+
+	// Check whether an ancestor is a leaf of the spec
+	segments := strings.Split(entry.path, "/")
+	for i := len(segments) - 1; i > 0; i-- {
+		ancestor := layoutEntry{strings.Join(segments[:i], "/"), true}
+		for _, pattern := range allowed {
+			if matchLayoutPattern(pattern, ancestor) && !hasLayoutChildPattern(pattern, allowed) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Report whether any allowed pattern declares contents below pattern
+func hasLayoutChildPattern(pattern string, allowed []string) bool {
+	prefix := strings.TrimSuffix(pattern, "/") + "/"
+	for _, other := range allowed {
+		if other != prefix && strings.HasPrefix(other, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchLayoutPattern(pattern string, entry layoutEntry) bool {
+	if strings.HasSuffix(pattern, "/") {
+		if !entry.isDir {
+			return false
+		}
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+	return matchGlob(strings.TrimPrefix(pattern, "./"), entry.path)
+}
+
+// Match a slash-separated path against a glob where "**" matches any number
+// of path segments and other segments use path.Match syntax.
+func matchGlob(pattern string, name string) bool {
+	return matchGlobSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchGlobSegments(pattern []string, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchGlobSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
@@ -0,0 +1,131 @@
+package treex
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"go.mod", "go.mod", true},
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/main.go", false},
+		{"cmd/*/main.go", "cmd/app/main.go", true},
+		{"**/*.pem", "secret.pem", true},
+		{"**/*.pem", "a/b/secret.pem", true},
+		{"docs/**", "docs/a/b.md", true},
+		{"docs/**", "src/a.md", false},
+	}
+
+	for _, tc := range testCases {
+		if result := matchGlob(tc.pattern, tc.name); result != tc.expected {
+			t.Errorf("matchGlob(%q, %q): expected %v, but got %v", tc.pattern, tc.name, tc.expected, result)
+		}
+	}
+}
+
+func TestParseLayoutYAML(t *testing.T) {
+	content := `# Service layout
+required:
+  - cmd/
+  - "go.mod"
+forbidden: ["**/*.pem", vendor/]
+optional:
+  - docs/
+strict: true
+`
+
+	spec, err := parseLayoutYAML(content)
+	if err != nil {
+		t.Fatalf("parseLayoutYAML error: %v", err)
+	}
+
+	expected := &LayoutSpec{
+		Required:  []string{"cmd/", "go.mod"},
+		Forbidden: []string{"**/*.pem", "vendor/"},
+		Optional:  []string{"docs/"},
+		Strict:    true,
+	}
+	if !reflect.DeepEqual(spec, expected) {
+		t.Errorf("Expected %+v, but got %+v", expected, spec)
+	}
+
+	if _, err := parseLayoutYAML("- orphan\n"); err == nil {
+		t.Error("Should fail on list items without a key")
+	}
+	if _, err := parseLayoutYAML("unknown: [a]\n"); err == nil {
+		t.Error("Should fail on unknown keys")
+	}
+}
+
+func TestParseLayoutListing(t *testing.T) {
+	listing := `./
+├── cmd/
+│   └── main.go
+├── docs/      # optional
+├── vendor/    # forbidden
+└── go.mod
+`
+
+	spec, err := parseLayoutListing(listing)
+	if err != nil {
+		t.Fatalf("parseLayoutListing error: %v", err)
+	}
+
+	expected := &LayoutSpec{
+		Required:  []string{"cmd/", "cmd/main.go", "go.mod"},
+		Forbidden: []string{"vendor/"},
+		Optional:  []string{"docs/"},
+		Strict:    true,
+	}
+	if !reflect.DeepEqual(spec, expected) {
+		t.Errorf("Expected %+v, but got %+v", expected, spec)
+	}
+}
+
+func TestCheckLayout(t *testing.T) {
+	root, err := parseTreeListing(`./
+├── cmd/
+│   ├── app/
+│   │   └── main.go
+│   └── notes.txt
+├── internal/
+│   └── store/
+│       └── store.go
+├── vendor/
+├── secret.pem
+└── go.mod
+`)
+	if err != nil {
+		t.Fatalf("parseTreeListing error: %v", err)
+	}
+
+	spec := &LayoutSpec{
+		Required:  []string{"cmd/*/main.go", "go.mod", "README.md", "internal/"},
+		Forbidden: []string{"vendor/", "**/*.pem"},
+		Optional:  []string{"docs/"},
+		Strict:    true,
+	}
+
+	expected := []LayoutViolation{
+		{Kind: "missing", Pattern: "README.md"},
+		{Kind: "forbidden", Pattern: "vendor/", Path: "vendor"},
+		{Kind: "forbidden", Pattern: "**/*.pem", Path: "secret.pem"},
+		{Kind: "unexpected", Path: "cmd/notes.txt"},
+	}
+
+	violations := CheckLayout(root, spec)
+	if !reflect.DeepEqual(violations, expected) {
+		t.Errorf("Expected %v, but got %v", expected, violations)
+	}
+
+	// Without strict mode, undeclared entries are fine
+	spec.Strict = false
+	if violations := CheckLayout(root, spec); len(violations) != 3 {
+		t.Errorf("Expected 3 violations without strict mode, but got %v", violations)
+	}
+}
@@ -0,0 +1,140 @@
+package treex
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Prefix of the names of nodes standing for entries left out by the limits
+const elidedPrefix = "… "
+
+// Truncate the children of every directory of t to opts.MaxEntries, and the
+// whole tree to opts.MaxTotal entries. Entries are kept breadth first, so the
+// upper levels are shown whole before deeper ones, and the entries left out
+// of a directory are replaced by a single summary node.
+func limitEntries(t *TreeNode, opts *Options) {
+	if opts.MaxEntries <= 0 && opts.MaxTotal <= 0 {
+		return
+	}
+
+	budget := opts.MaxTotal
+	queue := []*TreeNode{t}
+	for len(queue) > 0 {
+		dir := queue[0]
+		queue = queue[1:]
+
+		keep := len(dir.Children)
+		if opts.MaxEntries > 0 && keep > opts.MaxEntries {
+			keep = opts.MaxEntries
+		}
+		if opts.MaxTotal > 0 {
+			if keep > budget {
+				keep = budget
+			}
+			budget -= keep
+		}
+
+		if keep < len(dir.Children) {
+			elided := newElidedNode(dir.Children[keep:], dir.Depth+1, opts.GroupElided)
+			dir.Children = append(dir.Children[:keep:keep], elided)
+		}
+		for _, child := range dir.Children[:keep] {
+			if child.IsDir {
+				queue = append(queue, child)
+			}
+		}
+	}
+}
+
+// Create the node summarizing entries left out of a directory
+func newElidedNode(entries []*TreeNode, depth int, byExtension bool) *TreeNode {
+	node := &TreeNode{
+		Depth:  depth,
+		Elided: len(entries),
+	}
+	for _, entry := range entries {
+		node.Size += entry.Size
+	}
+	if byExtension {
+		node.Name = elidedPrefix + getElidedGroups(entries)
+	} else {
+		node.Name = elidedPrefix + getElidedCount(entries)
+	}
+	return node
+}
+
+// Describe the number of entries left out, e.g. "1234 more files"
+func getElidedCount(entries []*TreeNode) string {
+	dirs := 0
+	for _, entry := range entries {
+		if entry.IsDir {
+			dirs++
+		}
+	}
+
+	switch dirs {
+	case 0:
+		return pluralize(len(entries), "more file", "more files")
+	case len(entries):
+		return pluralize(len(entries), "more directory", "more directories")
+	default:
+		return pluralize(len(entries), "more entry", "more entries")
+	}
+}
+
+// Describe the entries left out by extension, most common first, e.g.
+// "900 .png, 34 .json, 2 directories"
+func getElidedGroups(entries []*TreeNode) string {
+	counts := map[string]int{}
+	dirs, others := 0, 0
+	for _, entry := range entries {
+		switch ext := path.Ext(entry.Name); {
+		case entry.IsDir:
+			dirs++
+		case ext == "" || ext == entry.Name:
+			others++
+		default:
+			counts[ext]++
+		}
+	}
+
+	extensions := make([]string, 0, len(counts))
+	for ext := range counts {
+		extensions = append(extensions, ext)
+	}
+	sort.Slice(extensions, func(i, j int) bool {
+		if counts[extensions[i]] != counts[extensions[j]] {
+			return counts[extensions[i]] > counts[extensions[j]]
+		}
+		return extensions[i] < extensions[j]
+	})
+
+	var groups []string
+	for _, ext := range extensions {
+		groups = append(groups, fmt.Sprintf("%d %s", counts[ext], ext))
+	}
+	if others > 0 {
+		groups = append(groups, pluralize(others, "other file", "other files"))
+	}
+	if dirs > 0 {
+		groups = append(groups, pluralize(dirs, "directory", "directories"))
+	}
+	return strings.Join(groups, ", ")
+}
+
+// Matches the count starting every group of a summary node's name
+var elidedCountPattern = regexp.MustCompile(`(?:^|, )(\d+) `)
+
+// Get the number of entries a summary node read from a listing stands for
+func parseElidedCount(name string) int {
+	count := 0
+	for _, match := range elidedCountPattern.FindAllStringSubmatch(strings.TrimPrefix(name, elidedPrefix), -1) {
+		n, _ := strconv.Atoi(match[1])
+		count += n
+	}
+	return count
+}
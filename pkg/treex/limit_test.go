@@ -0,0 +1,105 @@
+package treex
+
+import (
+	"fmt"
+	"testing"
+	"testing/fstest"
+)
+
+func createLimitTestFS() fstest.MapFS {
+	fsys := fstest.MapFS{
+		"docs/guide.md":       {Data: make([]byte, 5)},
+		"docs/api/index.md":   {Data: make([]byte, 5)},
+		"testdata/README":     {Data: make([]byte, 3)},
+		"testdata/cases/a.in": {Data: make([]byte, 1)},
+		"go.mod":              {Data: make([]byte, 2)},
+	}
+	for i := 0; i < 10; i++ {
+		fsys[fmt.Sprintf("testdata/img%d.png", i)] = &fstest.MapFile{Data: make([]byte, 10)}
+	}
+	fsys["testdata/x.json"] = &fstest.MapFile{Data: make([]byte, 4)}
+	return fsys
+}
+
+func TestLimitEntries(t *testing.T) {
+	testCases := []struct {
+		name     string
+		opts     Options
+		expected string
+	}{
+		{"per directory", Options{MaxEntries: 2}, `root/
+├── docs/
+│   ├── api/
+│   │   └── index.md
+│   └── guide.md
+├── go.mod
+└── … 1 more directory
+`},
+		{"grouped", Options{MaxEntries: 3, GroupElided: true}, `root/
+├── docs/
+│   ├── api/
+│   │   └── index.md
+│   └── guide.md
+├── go.mod
+└── testdata/
+    ├── README
+    ├── cases/
+    │   └── a.in
+    ├── img0.png
+    └── … 9 .png, 1 .json
+`},
+		{"total", Options{MaxTotal: 4}, `root/
+├── docs/
+│   ├── api/
+│   │   └── … 1 more file
+│   └── … 1 more file
+├── go.mod
+└── testdata/
+    └── … 13 more entries
+`},
+	}
+
+	for _, tc := range testCases {
+		node, err := Build(&Options{Dir: "root", FS: createLimitTestFS(), MaxEntries: tc.opts.MaxEntries, MaxTotal: tc.opts.MaxTotal, GroupElided: tc.opts.GroupElided})
+		if err != nil {
+			t.Fatalf("%s: Build error: %v", tc.name, err)
+		}
+		if result := node.ToTreeString(true, "", false); result != tc.expected {
+			t.Errorf("%s: expected:\n%s\nBut got:\n%s", tc.name, tc.expected, result)
+		}
+	}
+
+	// Summary nodes keep the size of the entries they stand for
+	node, _ := Build(&Options{Dir: "root", FS: createLimitTestFS(), MaxEntries: 1})
+	if elided := node.Children[1]; elided.Elided != 2 || elided.Size != node.Size-node.Children[0].Size {
+		t.Errorf("Unexpected summary node %+v in a directory of %d bytes", elided, node.Size)
+	}
+	if report := node.Report(); report.Elided != 3 || report.Size != 120 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+}
+
+func TestParseElidedCount(t *testing.T) {
+	testCases := map[string]int{
+		"… 1234 more files":                      1234,
+		"… 900 .png, 34 .mp3, 2 other files":     936,
+		"… 1 .json, 3 directories":               4,
+		"… 12 more entries":                      12,
+		"… 7 .tar.gz, 1 other file, 1 directory": 9,
+	}
+	for name, expected := range testCases {
+		if count := parseElidedCount(name); count != expected {
+			t.Errorf("%q: expected %d, but got %d", name, expected, count)
+		}
+	}
+
+	// Summary nodes survive a round trip through a listing
+	node, _ := Build(&Options{Dir: "root", FS: createLimitTestFS(), MaxEntries: 2, GroupElided: true})
+	parsed, err := ParseListing(node.ToTreeString(true, "", false), "tree")
+	if err != nil {
+		t.Fatalf("ParseListing error: %v", err)
+	}
+	if report := parsed.Report(); report.Elided != node.Report().Elided {
+		t.Errorf("Expected %d elided entries after parsing, but got %d", node.Report().Elided, report.Elided)
+	}
+}
@@ -0,0 +1,44 @@
+package treex
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ReadLinkFS is implemented by filesystems that can read symbolic links, like
+// fs.ReadLinkFS in newer Go versions. Build shows the targets of links in
+// filesystems that implement it.
+type ReadLinkFS interface {
+	fs.FS
+	ReadLink(name string) (string, error)
+}
+
+// dirFS is os.DirFS with support for reading symbolic links
+type dirFS struct {
+	fs.FS
+	dir string
+}
+
+func newDirFS(dir string) dirFS {
+	return dirFS{os.DirFS(dir), dir}
+}
+
+func (d dirFS) ReadLink(name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+	}
+	return os.Readlink(filepath.Join(d.dir, filepath.FromSlash(name)))
+}
+
+func (d dirFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(d.FS, name)
+}
+
+func (d dirFS) ReadFile(name string) ([]byte, error) {
+	return fs.ReadFile(d.FS, name)
+}
+
+func (d dirFS) Stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(d.FS, name)
+}
@@ -0,0 +1,105 @@
+package treex
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Create a directory with links to a file, a directory, a parent directory
+// and a missing target
+func setupLinkDir(t *testing.T) string {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "shared", "sub"), 0755)
+	os.MkdirAll(filepath.Join(dir, "app"), 0755)
+	os.WriteFile(filepath.Join(dir, "shared", "a.txt"), []byte("hi\n"), 0644)
+
+	links := map[string]string{
+		"alink":    "shared/a.txt",
+		"app/lib":  "../shared",
+		"app/up":   "..",
+		"dangling": "nowhere",
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(dir, name)); err != nil {
+			t.Skipf("Symbolic links not supported: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestWalkTreeLinks(t *testing.T) {
+	dir := setupLinkDir(t)
+
+	node, err := walkTree(newDirFS(dir), "root", &Options{}, NewFilter("", false))
+	if err != nil {
+		t.Fatalf("walkTree error: %v", err)
+	}
+	expected := `root/
+├── alink -> shared/a.txt
+├── app/
+│   ├── lib -> ../shared
+│   └── up -> ..
+├── dangling -> nowhere [broken link]
+└── shared/
+    ├── a.txt
+    └── sub/
+`
+	if result := node.ToTreeString(true, "", false); result != expected {
+		t.Errorf("Expected:\n%s\nBut got:\n%s", expected, result)
+	}
+
+	// Followed links are descended into, except those back to a parent
+	expected = `root/
+├── alink -> shared/a.txt
+├── app/
+│   ├── lib -> ../shared/
+│   │   ├── a.txt
+│   │   └── sub/
+│   └── up -> ../ [recursive, not followed]
+├── dangling -> nowhere [broken link]
+└── shared/
+    ├── a.txt
+    └── sub/
+`
+	for _, jobs := range []int{1, 4} {
+		node, err := walkTree(newDirFS(dir), "root", &Options{FollowLinks: true, Jobs: jobs}, NewFilter("", false))
+		if err != nil {
+			t.Fatalf("walkTree error with %d jobs: %v", jobs, err)
+		}
+		if result := node.ToTreeString(true, "", false); result != expected {
+			t.Errorf("With %d jobs, expected:\n%s\nBut got:\n%s", jobs, expected, result)
+		}
+		if alink := node.Children[0]; alink.Size != 3 || alink.Mode&os.ModeSymlink == 0 {
+			t.Errorf("Followed links should have the size of their target and a link mode, got %d %v", alink.Size, alink.Mode)
+		}
+	}
+}
+
+func TestParseLinkListing(t *testing.T) {
+	dir := setupLinkDir(t)
+	node, _ := walkTree(newDirFS(dir), "root", &Options{FollowLinks: true}, NewFilter("", false))
+
+	// Link targets and marks survive a round trip through the listing formats
+	for _, format := range []string{"tree", "json"} {
+		var listing string
+		if format == "tree" {
+			listing = node.ToTreeString(true, "", false)
+		} else {
+			listing = node.ToJSONString()
+		}
+
+		parsed, err := ParseListing(listing, format)
+		if err != nil {
+			t.Fatalf("%s: ParseListing error: %v", format, err)
+		}
+		up := parsed.Children[1].Children[1]
+		dangling := parsed.Children[2]
+		if up.Name != "up" || up.LinkTarget != ".." || !up.Recursive || !up.IsDir {
+			t.Errorf("%s: unexpected recursive link %+v", format, up)
+		}
+		if dangling.Name != "dangling" || dangling.LinkTarget != "nowhere" || !dangling.LinkBroken {
+			t.Errorf("%s: unexpected broken link %+v", format, dangling)
+		}
+	}
+}
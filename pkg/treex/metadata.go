@@ -0,0 +1,71 @@
+package treex
+
+import (
+	"archive/tar"
+	"io/fs"
+	"os/user"
+	"strconv"
+	"sync"
+)
+
+// fileStat is the part of a Unix stat result treex shows
+type fileStat struct {
+	uid, gid     uint64
+	inode, links uint64
+}
+
+// Names of user and group IDs, looked up once per ID
+var (
+	userNames  sync.Map
+	groupNames sync.Map
+)
+
+// Record the owner, group, inode and link count of info on t, where the
+// platform or archive provides them
+func (t *TreeNode) setMetadata(info fs.FileInfo) {
+	if header, ok := info.Sys().(*tar.Header); ok {
+		t.Owner, t.Group = header.Uname, header.Gname
+		if t.Owner == "" {
+			t.Owner = strconv.Itoa(header.Uid)
+		}
+		if t.Group == "" {
+			t.Group = strconv.Itoa(header.Gid)
+		}
+		return
+	}
+
+	st, ok := getFileStat(info)
+	if !ok {
+		return
+	}
+	t.Owner = lookupName(&userNames, st.uid, func(id string) (string, error) {
+		u, err := user.LookupId(id)
+		if err != nil {
+			return "", err
+		}
+		return u.Username, nil
+	})
+	t.Group = lookupName(&groupNames, st.gid, func(id string) (string, error) {
+		g, err := user.LookupGroupId(id)
+		if err != nil {
+			return "", err
+		}
+		return g.Name, nil
+	})
+	t.Inode, t.HardLinks = st.inode, st.links
+}
+
+// Get the name of an ID from cache or with lookup, falling back to the ID
+// itself for IDs without a name
+func lookupName(cache *sync.Map, id uint64, lookup func(id string) (string, error)) string {
+	if name, ok := cache.Load(id); ok {
+		return name.(string)
+	}
+	idString := strconv.FormatUint(id, 10)
+	name, err := lookup(idString)
+	if err != nil || name == "" {
+		name = idString
+	}
+	cache.Store(id, name)
+	return name
+}
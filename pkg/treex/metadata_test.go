@@ -0,0 +1,99 @@
+package treex
+
+import (
+	"archive/tar"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestMetadata(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Owners and inodes are only read on Unix")
+	}
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0644)
+	os.Link(filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt"))
+
+	node, err := walkTree(newDirFS(dir), "root", &Options{Metadata: true}, NewFilter("", false))
+	if err != nil {
+		t.Fatalf("walkTree error: %v", err)
+	}
+
+	current, err := user.Current()
+	if err != nil {
+		t.Skipf("Current user unknown: %v", err)
+	}
+	a, b := node.Children[0], node.Children[1]
+	if a.Owner != current.Username || a.Group == "" || node.Owner != current.Username {
+		t.Errorf("Expected entries owned by %s, got %q:%q", current.Username, a.Owner, a.Group)
+	}
+	if a.Inode == 0 || a.Inode != b.Inode || a.HardLinks != 2 {
+		t.Errorf("Expected hard links to share an inode, got %d/%d with %d links", a.Inode, b.Inode, a.HardLinks)
+	}
+
+	// Nothing is recorded unless asked for
+	node, _ = walkTree(newDirFS(dir), "root", &Options{}, NewFilter("", false))
+	if child := node.Children[0]; child.Owner != "" || child.Inode != 0 {
+		t.Errorf("Metadata shouldn't be recorded by default, got %+v", child)
+	}
+}
+
+func TestTarMetadata(t *testing.T) {
+	header := &tar.Header{Name: "app", Mode: 0755, Uid: 1000, Gid: 50, Uname: "deploy"}
+	node := &TreeNode{}
+	node.setMetadata(header.FileInfo())
+	if node.Owner != "deploy" || node.Group != "50" {
+		t.Errorf("Expected deploy:50, got %s:%s", node.Owner, node.Group)
+	}
+}
+
+func TestRenderMetadata(t *testing.T) {
+	tree := &TreeNode{Name: "root", IsDir: true, Mode: fs.ModeDir | 0750, Owner: "root", Group: "wheel", Inode: 12, HardLinks: 3, Children: []*TreeNode{
+		{Name: "run.sh", Depth: 1, Size: 2048, Mode: 0700, Owner: "deploy", Group: "staff", Inode: 345, HardLinks: 1},
+		{Name: "… 2 more files", Depth: 1, Elided: 2},
+	}}
+
+	renderer, _ := NewRenderer("tree", RenderOptions{Perms: true, Owner: true, Group: true, Inode: true, HardLinks: true, Sizes: true})
+	var result strings.Builder
+	renderer.Render(&result, tree)
+	expected := `[       12   3 drwxr-x--- root     wheel       0B]  root/
+├── [      345   1 -rwx------ deploy   staff     2.0K]  run.sh
+└── [                                              0B]  … 2 more files
+`
+	if result.String() != expected {
+		t.Errorf("Expected:\n%s\nBut got:\n%s", expected, result.String())
+	}
+
+	// Columns are stripped when parsing the listing back
+	parsed, err := ParseListing(result.String(), "tree")
+	if err != nil || !equalTrees(parsed, tree) {
+		t.Errorf("Listing with metadata doesn't parse back: %v", err)
+	}
+
+	renderer, _ = NewRenderer("md-table", RenderOptions{Perms: true, Owner: true})
+	result.Reset()
+	renderer.Render(&result, tree)
+	expected = "| Path | Type | Size | Mode | Owner | Description |\n" +
+		"|------|------|------|------|-------|-------------|\n" +
+		"| `root/` | directory | 0B | `drwxr-x---` | root | |\n" +
+		"| `root/run.sh` | file | 2.0K | `-rwx------` | deploy | |\n" +
+		"| `root/… 2 more files` | elided | 0B | | | |\n"
+	if result.String() != expected {
+		t.Errorf("Expected:\n%s\nBut got:\n%s", expected, result.String())
+	}
+
+	// JSON has the metadata fields and reads them back
+	parsed, err = ParseListing(tree.ToJSONString(), "json")
+	if err != nil {
+		t.Fatalf("ParseListing error: %v", err)
+	}
+	if child := parsed.Children[0]; child.Owner != "deploy" || child.Group != "staff" || child.Inode != 345 || child.HardLinks != 1 {
+		t.Errorf("Unexpected metadata after a JSON round trip: %+v", child)
+	}
+}
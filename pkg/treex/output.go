@@ -0,0 +1,494 @@
+package treex
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// File type icons by lowercase extension
+var extensionIcons = map[string]string{
+	// Go file
+	".go": "🔹",
+	// Python file
+	".py": "🐍",
+	// JavaScript/TypeScript file
+	".js":  "📜",
+	".jsx": "📜",
+	".ts":  "📜",
+	".tsx": "📜",
+	// HTML file
+	".html": "🌐",
+	".htm":  "🌐",
+	// CSS file
+	".css": "🎨",
+	// Markdown file
+	".md": "📝",
+	// JSON file
+	".json": "📋",
+	// XML file
+	".xml": "📋",
+	// YAML file
+	".yml":  "⚙️",
+	".yaml": "⚙️",
+	// Plain text file
+	".txt": "📄",
+	// Image file
+	".png":  "🖼️",
+	".jpg":  "🖼️",
+	".jpeg": "🖼️",
+	".gif":  "🖼️",
+	".bmp":  "🖼️",
+	".svg":  "🖼️",
+	// Audio file
+	".mp3": "🎵",
+	".wav": "🎵",
+	".ogg": "🎵",
+	// Video file
+	".mp4": "🎬",
+	".avi": "🎬",
+	".mkv": "🎬",
+	".mov": "🎬",
+	// PDF file
+	".pdf": "📕",
+	// Archive file
+	".zip": "📦",
+	".tar": "📦",
+	".gz":  "📦",
+	".7z":  "📦",
+	".rar": "📦",
+	// Executable file
+	".exe": "⚡",
+	".dll": "⚡",
+	// Script file
+	".sh":   "⚙️",
+	".bash": "⚙️",
+	".zsh":  "⚙️",
+	".ps1":  "⚙️",
+	// C/C++ file
+	".c":   "🔧",
+	".cpp": "🔧",
+	".h":   "🔧",
+	".hpp": "🔧",
+	// Java file
+	".java": "☕",
+	// Ruby file
+	".rb": "💎",
+	// PHP file
+	".php": "🐘",
+	// Rust file
+	".rs": "🦀",
+	// SQL file
+	".sql": "🗄️",
+	// Ignore file
+	".gitignore":    "🔒",
+	".dockerignore": "🔒",
+}
+
+const (
+	folderIcon = "📁"
+	fileIcon   = "📄" // unknown extensions
+)
+
+// Get file type icon
+func getFileIcon(name string, isDir bool) string {
+	if isDir {
+		return folderIcon + " "
+	}
+	if icon, ok := extensionIcons[strings.ToLower(filepath.Ext(name))]; ok {
+		return icon + " "
+	}
+	return fileIcon + " "
+}
+
+// Report whether s is one of the icons returned by getFileIcon, without its
+// trailing space
+func isFileIcon(s string) bool {
+	if s == folderIcon || s == fileIcon {
+		return true
+	}
+	for _, icon := range extensionIcons {
+		if s == icon {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *TreeNode) getEntryString(opts RenderOptions) string {
+	s := opts.Colors.paintEntry(t, t.Name)
+	if t.LinkBroken {
+		s += " -> " + opts.Colors.paintMissing(t.LinkTarget)
+	} else if t.LinkTarget != "" {
+		s += " -> " + t.LinkTarget
+	}
+	if t.IsDir {
+		s += "/"
+	}
+	if opts.Icons && t.Elided == 0 {
+		s = getFileIcon(t.Name, t.IsDir) + s
+	}
+	s = t.getInfoString(opts) + s
+	for _, mark := range t.getMarks() {
+		s += " " + mark
+	}
+	return s
+}
+
+// Get the columns shown before entries, like `tree -h` or `tree -pug
+// --inodes`: inode, hard links, permissions, owner, group and size. Summary
+// nodes only have a size.
+func (t *TreeNode) getInfoString(opts RenderOptions) string {
+	var columns []string
+	add := func(value string) {
+		if t.Elided > 0 {
+			value = strings.Repeat(" ", utf8.RuneCountInString(value))
+		}
+		columns = append(columns, value)
+	}
+
+	if opts.Inode {
+		add(fmt.Sprintf("%9d", t.Inode))
+	}
+	if opts.HardLinks {
+		add(fmt.Sprintf("%3d", t.HardLinks))
+	}
+	if opts.Perms {
+		add(t.Mode.String())
+	}
+	if opts.Owner {
+		add(fmt.Sprintf("%-8s", t.Owner))
+	}
+	if opts.Group {
+		add(fmt.Sprintf("%-8s", t.Group))
+	}
+	if opts.Sizes {
+		columns = append(columns, fmt.Sprintf("%5s", formatSize(t.Size)))
+	}
+
+	if len(columns) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(columns, " ") + "]  "
+}
+
+// errWriter remembers the first error of the underlying writer and drops all
+// later writes, so renderers can write freely and check once at the end.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) Write(p []byte) (int, error) {
+	if ew.err != nil {
+		return 0, ew.err
+	}
+	n, err := ew.w.Write(p)
+	ew.err = err
+	return n, err
+}
+
+// Render into a string with one of the write functions below
+func renderString(write func(w io.Writer)) string {
+	var sb strings.Builder
+	write(&sb)
+	return sb.String()
+}
+
+func (t *TreeNode) ToIndentString(spaces int, useIcons bool) string {
+	opts := RenderOptions{Icons: useIcons}
+	return renderString(func(w io.Writer) {
+		t.writeIndent(w, spaces, opts, t.getDescriptionColumn(spaces, opts))
+	})
+}
+
+func (t *TreeNode) writeIndent(w io.Writer, spaces int, opts RenderOptions, descColumn int) {
+	nodeName := t.getEntryString(opts)
+	io.WriteString(w, strings.Repeat(" ", t.Depth*spaces)+nodeName+t.getDescriptionComment(t.Depth*spaces+displayWidth(nodeName), descColumn)+"\n")
+
+	for _, child := range t.Children {
+		child.writeIndent(w, spaces, opts, descColumn)
+	}
+}
+
+func (t *TreeNode) ToTreeString(isLast bool, prefix string, useIcons bool) string {
+	opts := RenderOptions{Icons: useIcons}
+	return renderString(func(w io.Writer) {
+		t.writeTree(w, isLast, prefix, opts, t.getDescriptionColumn(4, opts))
+	})
+}
+
+func (t *TreeNode) writeTree(w io.Writer, isLast bool, prefix string, opts RenderOptions, descColumn int) {
+	currentPrefix := prefix
+
+	// current node prefix
+	if t.Depth > 0 {
+		if isLast {
+			currentPrefix += "└── "
+		} else {
+			currentPrefix += "├── "
+		}
+	}
+
+	nodeName := t.getEntryString(opts)
+
+	io.WriteString(w, opts.Colors.paintConnector(currentPrefix)+nodeName+t.getDescriptionComment(displayWidth(currentPrefix+nodeName), descColumn)+"\n")
+
+	// sub node prefix
+	childPrefix := prefix
+	if t.Depth > 0 {
+		if isLast {
+			childPrefix += "    "
+		} else {
+			childPrefix += "│   "
+		}
+	}
+
+	for i, child := range t.Children {
+		isLastChild := i == len(t.Children)-1
+		child.writeTree(w, isLastChild, childPrefix, opts, descColumn)
+	}
+}
+
+func (t *TreeNode) ToMarkdownString(level int, useIcons bool) string {
+	return renderString(func(w io.Writer) {
+		t.writeMarkdown(w, level, RenderOptions{Icons: useIcons})
+	})
+}
+
+func (t *TreeNode) writeMarkdown(w io.Writer, level int, opts RenderOptions) {
+	line := strings.Repeat("  ", level) + "- " + t.getEntryString(opts)
+	if t.Description != "" {
+		line += " — " + t.Description
+	}
+	io.WriteString(w, line+"\n")
+
+	// Process child nodes
+	for _, child := range t.Children {
+		child.writeMarkdown(w, level+1, opts)
+	}
+}
+
+// ToMarkdownLinkString renders a Markdown list where every entry links to its
+// path relative to the working directory, optionally prefixed with baseURL.
+func (t *TreeNode) ToMarkdownLinkString(level int, useIcons bool, nodePath string, baseURL string) string {
+	return renderString(func(w io.Writer) {
+		t.writeMarkdownLinks(w, level, RenderOptions{Icons: useIcons, BaseURL: baseURL}, nodePath)
+	})
+}
+
+func (t *TreeNode) writeMarkdownLinks(w io.Writer, level int, opts RenderOptions, nodePath string) {
+	line := strings.Repeat("  ", level) + "- " + t.getInfoString(opts)
+	if t.Elided > 0 {
+		// Summary nodes have nothing to link to
+		io.WriteString(w, line+t.Name+"\n")
+		return
+	}
+	if opts.Icons {
+		line += getFileIcon(t.Name, t.IsDir)
+	}
+	line += "[" + escapeMarkdownLabel(t.getEntryString(RenderOptions{})) + "](" + getLinkTarget(nodePath, t.IsDir, opts.BaseURL) + ")"
+	if t.Description != "" {
+		line += " — " + t.Description
+	}
+	io.WriteString(w, line+"\n")
+
+	// Process child nodes
+	for _, child := range t.Children {
+		child.writeMarkdownLinks(w, level+1, opts, path.Join(nodePath, child.Name))
+	}
+}
+
+// Build a URL-encoded link target for a slash-separated relative path
+func getLinkTarget(nodePath string, isDir bool, baseURL string) string {
+	segments := strings.Split(nodePath, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	target := strings.Join(segments, "/")
+	if isDir {
+		target += "/"
+	}
+
+	if baseURL != "" {
+		if !strings.HasSuffix(baseURL, "/") {
+			baseURL += "/"
+		}
+		target = baseURL + strings.TrimPrefix(target, "./")
+	}
+	return target
+}
+
+// Escape characters that would end or break a Markdown link label
+func escapeMarkdownLabel(label string) string {
+	replacer := strings.NewReplacer("\\", "\\\\", "[", "\\[", "]", "\\]")
+	return replacer.Replace(label)
+}
+
+// ToMarkdownCodeString wraps the tree format in a fenced code block
+func (t *TreeNode) ToMarkdownCodeString(useIcons bool) string {
+	return renderString(func(w io.Writer) {
+		t.writeMarkdownCode(w, RenderOptions{Icons: useIcons})
+	})
+}
+
+func (t *TreeNode) writeMarkdownCode(w io.Writer, opts RenderOptions) {
+	opts.Colors = nil
+	io.WriteString(w, "```text\n")
+	t.writeTree(w, true, "", opts, t.getDescriptionColumn(4, opts))
+	if opts.Report {
+		t.writeReport(w)
+	}
+	io.WriteString(w, "```\n")
+}
+
+// ToMarkdownTableString renders one table row per entry
+func (t *TreeNode) ToMarkdownTableString(useIcons bool) string {
+	return renderString(func(w io.Writer) {
+		t.writeMarkdownTable(w, RenderOptions{Icons: useIcons})
+	})
+}
+
+// Optional metadata columns of the md-table format, shown between Size and
+// Description
+var tableColumns = []struct {
+	name    string
+	enabled func(opts RenderOptions) bool
+	value   func(t *TreeNode) string
+}{
+	{"Inode", func(opts RenderOptions) bool { return opts.Inode }, func(t *TreeNode) string { return strconv.FormatUint(t.Inode, 10) }},
+	{"Links", func(opts RenderOptions) bool { return opts.HardLinks }, func(t *TreeNode) string { return strconv.FormatUint(t.HardLinks, 10) }},
+	{"Mode", func(opts RenderOptions) bool { return opts.Perms }, func(t *TreeNode) string { return "`" + t.Mode.String() + "`" }},
+	{"Owner", func(opts RenderOptions) bool { return opts.Owner }, func(t *TreeNode) string { return escapeMarkdownCell(t.Owner) }},
+	{"Group", func(opts RenderOptions) bool { return opts.Group }, func(t *TreeNode) string { return escapeMarkdownCell(t.Group) }},
+}
+
+func (t *TreeNode) writeMarkdownTable(w io.Writer, opts RenderOptions) {
+	header, separator := "| Path | Type | Size |", "|------|------|------|"
+	for _, column := range tableColumns {
+		if column.enabled(opts) {
+			header += " " + column.name + " |"
+			separator += strings.Repeat("-", len(column.name)+2) + "|"
+		}
+	}
+	io.WriteString(w, header+" Description |\n")
+	io.WriteString(w, separator+"-------------|\n")
+	t.writeMarkdownTableRows(w, t.Name, opts)
+}
+
+func (t *TreeNode) writeMarkdownTableRows(w io.Writer, nodePath string, opts RenderOptions) {
+	entryPath := nodePath
+	if t.LinkTarget != "" {
+		entryPath += " -> " + t.LinkTarget
+	}
+	entryType := "file"
+	if t.IsDir {
+		entryPath += "/"
+		entryType = "directory"
+	} else if t.Elided > 0 {
+		entryType = "elided"
+	}
+
+	var icon string
+	if opts.Icons && t.Elided == 0 {
+		icon = getFileIcon(t.Name, t.IsDir)
+	}
+
+	description := strings.TrimSpace(strings.Join(append(t.getMarks(), t.Description), " "))
+	description = escapeMarkdownCell(description)
+	if description != "" {
+		description += " "
+	}
+
+	var metadata string
+	for _, column := range tableColumns {
+		if column.enabled(opts) {
+			// Summary nodes have no metadata
+			if t.Elided > 0 {
+				metadata += " |"
+			} else {
+				metadata += " " + column.value(t) + " |"
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "| %s`%s` | %s | %s |%s %s|\n", icon, escapeMarkdownCell(entryPath), entryType, formatSize(t.Size), metadata, description)
+
+	// Process child nodes
+	for _, child := range t.Children {
+		child.writeMarkdownTableRows(w, path.Join(nodePath, child.Name), opts)
+	}
+}
+
+// Escape pipes so they don't split a Markdown table cell
+func escapeMarkdownCell(cell string) string {
+	return strings.ReplaceAll(cell, "|", "\\|")
+}
+
+func (t *TreeNode) ToMermaidString() string {
+	return renderString(t.writeMermaid)
+}
+
+func (t *TreeNode) writeMermaid(w io.Writer) {
+	io.WriteString(w, "graph TD\n") // Mermaid graph directive
+	t.writeMermaidNodes(w, "", 1)
+}
+
+// Write the node with the given ID and its children, and return the next
+// unused ID
+func (t *TreeNode) writeMermaidNodes(w io.Writer, parentID string, nodeID int) int {
+	currentID := fmt.Sprintf("N%d", nodeID)
+
+	// Add current node, quoting labels with descriptions, link targets, marks
+	// or summaries
+	if t.Description != "" || t.LinkTarget != "" || t.Elided > 0 || len(t.getMarks()) > 0 {
+		label := t.getEntryString(RenderOptions{})
+		if t.Description != "" {
+			label += "<br/>" + t.Description
+		}
+		fmt.Fprintf(w, "    %s[\"%s\"]\n", currentID, strings.ReplaceAll(label, "\"", "#quot;"))
+	} else if t.IsDir {
+		fmt.Fprintf(w, "    %s[%s/]\n", currentID, t.Name)
+	} else {
+		fmt.Fprintf(w, "    %s[%s]\n", currentID, t.Name)
+	}
+
+	if parentID != "" {
+		fmt.Fprintf(w, "    %s --> %s\n", parentID, currentID)
+	}
+
+	// Process child nodes
+	nextID := nodeID + 1
+	for _, child := range t.Children {
+		nextID = child.writeMermaidNodes(w, currentID, nextID)
+	}
+	return nextID
+}
+
+// Write the report after a blank line, as `tree` does
+func (t *TreeNode) writeReport(w io.Writer) {
+	io.WriteString(w, "\n"+t.Report().String()+"\n")
+}
+
+// Format a byte count in a short human-readable form, e.g. 1.5K
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+
+	units := "KMGTPE"
+	value := float64(size) / unit
+	i := 0
+	for value >= unit && i < len(units)-1 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f%c", value, units[i])
+}
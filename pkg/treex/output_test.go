@@ -1,6 +1,7 @@
-package main
+package treex
 
 import (
+	"errors"
 	"strings"
 	"testing"
 )
@@ -202,3 +203,118 @@ func TestToMermaidString(t *testing.T) {
 		t.Error("Mermaid output should contain incremental node IDs")
 	}
 }
+
+func TestToMarkdownLinkString(t *testing.T) {
+	tree := createTestTree()
+	tree.Name = "."
+	tree.Children[1].Name = "my file[1].txt"
+
+	result := tree.ToMarkdownLinkString(0, false, tree.Name, "")
+	expectedPatterns := []string{
+		"- [./](./)",
+		"  - [dir1/](dir1/)",
+		"    - [file2.go](dir1/file2.go)",
+		"  - [my file\\[1\\].txt](my%20file%5B1%5D.txt)",
+	}
+
+	for _, pattern := range expectedPatterns {
+		if !strings.Contains(result, pattern) {
+			t.Errorf("Markdown link output missing expected pattern: %s\n%s", pattern, result)
+		}
+	}
+
+	// Test base URL prefix
+	result = tree.ToMarkdownLinkString(0, false, tree.Name, "https://example.com/tree/main")
+	if !strings.Contains(result, "- [dir1/](https://example.com/tree/main/dir1/)") {
+		t.Errorf("Markdown link output should use the base URL:\n%s", result)
+	}
+
+	// Icons stay outside the link label
+	result = tree.ToMarkdownLinkString(0, true, tree.Name, "")
+	if !strings.Contains(result, "- 📁 [dir1/](dir1/)") {
+		t.Errorf("Icon should be placed before the link:\n%s", result)
+	}
+}
+
+func TestToMarkdownCodeString(t *testing.T) {
+	tree := createTestTree()
+	result := tree.ToMarkdownCodeString(false)
+
+	if !strings.HasPrefix(result, "```text\n") || !strings.HasSuffix(result, "\n```\n") {
+		t.Errorf("Output should be wrapped in a fenced code block:\n%s", result)
+	}
+	if !strings.Contains(result, tree.ToTreeString(true, "", false)) {
+		t.Errorf("Fenced output should contain the tree format:\n%s", result)
+	}
+}
+
+func TestToMarkdownTableString(t *testing.T) {
+	tree := createTestTree()
+	tree.Children[1].Name = "a|b.txt"
+	tree.Children[1].Size = 2048
+
+	result := tree.ToMarkdownTableString(false)
+	expectedLines := []string{
+		"| Path | Type | Size | Description |",
+		"|------|------|------|-------------|",
+		"| `root/` | directory | 0B | |",
+		"| `root/dir1/` | directory | 0B | |",
+		"| `root/dir1/file2.go` | file | 0B | |",
+		"| `root/a\\|b.txt` | file | 2.0K | |",
+	}
+
+	lines := strings.Split(strings.TrimSuffix(result, "\n"), "\n")
+	if len(lines) != len(expectedLines) {
+		t.Fatalf("Expected %d lines, but got %d:\n%s", len(expectedLines), len(lines), result)
+	}
+	for i, line := range expectedLines {
+		if lines[i] != line {
+			t.Errorf("Line %d: expected %q, but got %q", i, line, lines[i])
+		}
+	}
+
+	// Test icon mode
+	iconResult := tree.ToMarkdownTableString(true)
+	if !strings.Contains(iconResult, "| 📁 `root/dir1/` |") {
+		t.Errorf("Icon mode should display folder icon for directories:\n%s", iconResult)
+	}
+}
+
+func TestOpenErrorMark(t *testing.T) {
+	tree := createTestTree()
+	tree.Children[0].Children = nil
+	tree.Children[0].Err = errors.New("open dir1: permission denied")
+
+	outputs := map[string]string{
+		"tree":     tree.ToTreeString(true, "", false),
+		"indent":   tree.ToIndentString(2, false),
+		"md":       tree.ToMarkdownString(0, false),
+		"md-table": tree.ToMarkdownTableString(false),
+		"mermaid":  tree.ToMermaidString(),
+		"json":     tree.ToJSONString(),
+	}
+	expected := map[string]string{
+		"tree":     "├── dir1/ [error opening dir]\n",
+		"indent":   "  dir1/ [error opening dir]\n",
+		"md":       "  - dir1/ [error opening dir]\n",
+		"md-table": "| `root/dir1/` | directory | 0B | [error opening dir] |\n",
+		"mermaid":  "    N2[\"dir1/ [error opening dir]\"]\n",
+		"json":     `"error": "open dir1: permission denied"`,
+	}
+	for format, output := range outputs {
+		if !strings.Contains(output, expected[format]) {
+			t.Errorf("%s: expected %q in:\n%s", format, expected[format], output)
+		}
+	}
+
+	// Marks survive a round trip through listings
+	for _, format := range []string{"tree", "json"} {
+		parsed, err := ParseListing(outputs[format], format)
+		if err != nil {
+			t.Fatalf("%s: ParseListing error: %v", format, err)
+		}
+		if dir := parsed.Children[0]; dir.Name != "dir1" || !dir.IsDir || dir.Err == nil {
+			t.Errorf("%s: expected dir1 to be marked, got %+v", format, dir)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package treex
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// parsedLine is one entry of a tree listing along with its indentation column
+type parsedLine struct {
+	column int
+	node   *TreeNode
+}
+
+// ParseListing parses a saved listing in one of the tree, indent, md or json
+// formats back into a tree. An empty format detects Markdown lists and reads
+// anything else as a tree or indent listing.
+func ParseListing(text string, format string) (*TreeNode, error) {
+	switch format {
+	case "":
+		if isMarkdownListing(text) {
+			return parseMarkdownListing(text)
+		}
+		return parseTreeListing(text)
+	case "tree", "indent":
+		return parseTreeListing(text)
+	case "md":
+		return parseMarkdownListing(text)
+	case "json":
+		return parseJSONListing(text)
+	default:
+		return nil, fmt.Errorf("unknown inputFormat '%s'", format)
+	}
+}
+
+// Report whether every entry of a listing is a Markdown list item
+func isMarkdownListing(text string) bool {
+	found := false
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "```") || isReportLine(line) {
+			continue
+		}
+		if !strings.HasPrefix(line, "- ") && !strings.HasPrefix(line, "* ") {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// Parse a Markdown list produced by the md format, with or without links.
+// Text after " — " becomes the description.
+func parseMarkdownListing(text string) (*TreeNode, error) {
+	var lines []parsedLine
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" || strings.HasPrefix(trimmed, "```") || isReportLine(trimmed) {
+			continue
+		}
+		if !strings.HasPrefix(trimmed, "- ") && !strings.HasPrefix(trimmed, "* ") {
+			return nil, fmt.Errorf("invalid list item %q", line)
+		}
+
+		column := len(line) - len(trimmed)
+		entry := strings.TrimSpace(trimmed[2:])
+
+		var description string
+		if i := strings.Index(entry, " — "); i >= 0 {
+			description = strings.TrimSpace(entry[i+len(" — "):])
+			entry = entry[:i]
+		}
+
+		node := parseListingEntry(unwrapMarkdownLink(stripInfoColumns(entry)))
+		node.Description = description
+		if node.Name == "" {
+			return nil, fmt.Errorf("invalid entry %q", line)
+		}
+		lines = append(lines, parsedLine{column, node})
+	}
+
+	return buildListingTree(lines)
+}
+
+// Get the label of a "[label](target)" link, keeping any icon before it
+func unwrapMarkdownLink(entry string) string {
+	start := strings.Index(entry, "[")
+	end := strings.LastIndex(entry, "](")
+	if start < 0 || end < start || !strings.HasSuffix(entry, ")") {
+		return entry
+	}
+
+	label := entry[start+1 : end]
+	label = strings.NewReplacer("\\[", "[", "\\]", "]", "\\\\", "\\").Replace(label)
+	return entry[:start] + label
+}
+
+// Parse a listing produced by the tree or indent formats (or by the `tree`
+// command) back into a tree. Nesting is derived from the column at which each
+// name starts, so any consistent indentation works. Entries ending with "/"
+// or having children are directories, and trailing "  # text" comments become
+// descriptions. A listing with a single top-level entry uses it as the root;
+// otherwise the entries are placed under a "." root.
+func parseTreeListing(text string) (*TreeNode, error) {
+	var lines []parsedLine
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		// Skip blank lines, code fences around pasted listings and reports
+		if trimmed := strings.TrimSpace(line); trimmed == "" || strings.HasPrefix(trimmed, "```") || isReportLine(trimmed) {
+			continue
+		}
+
+		column, rest := splitTreePrefix(line)
+		node := parseListingEntry(rest)
+		if node.Name == "" {
+			return nil, fmt.Errorf("invalid entry %q", line)
+		}
+		lines = append(lines, parsedLine{column, node})
+	}
+
+	return buildListingTree(lines)
+}
+
+// Arrange parsed lines into a tree, nesting each line under the closest
+// previous line with a smaller column.
+func buildListingTree(lines []parsedLine) (*TreeNode, error) {
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("listing has no entries")
+	}
+
+	var topLevel []*TreeNode
+	var stack []parsedLine
+	for _, line := range lines {
+		for len(stack) > 0 && stack[len(stack)-1].column >= line.column {
+			stack = stack[:len(stack)-1]
+		}
+
+		if len(stack) == 0 {
+			topLevel = append(topLevel, line.node)
+		} else {
+			parent := stack[len(stack)-1].node
+			parent.IsDir = true
+			parent.Children = append(parent.Children, line.node)
+		}
+		stack = append(stack, line)
+	}
+
+	root := topLevel[0]
+	if len(topLevel) > 1 || !root.IsDir {
+		root = &TreeNode{
+			Name:     ".",
+			IsDir:    true,
+			Children: topLevel,
+		}
+	}
+	setDepth(root, 0)
+	return root, nil
+}
+
+// Split the connector/indentation prefix off a listing line, returning the
+// column at which the entry starts and the remaining text.
+func splitTreePrefix(line string) (int, string) {
+	column := 0
+	var previous rune
+	for i, r := range line {
+		switch {
+		case r == ' ' || r == '\u00a0' || r == '│' || r == '├' || r == '└' || r == '─' || r == '|' || r == '`':
+		case r == '\t':
+			column += 3 // tabs count as four columns
+		case r == '-' && (previous == '|' || previous == '`' || previous == '-'):
+			// ASCII connectors as printed by `tree --charset ascii`: "|-- ", "`-- "
+		default:
+			return column, line[i:]
+		}
+		column++
+		previous = r
+	}
+	return column, ""
+}
+
+// Parse the text of a single entry: an optional icon, the name, an optional
+// " -> target" for links, a trailing "/" for directories, markers and an
+// optional "  # description" comment.
+func parseListingEntry(text string) *TreeNode {
+	node := &TreeNode{}
+
+	if i := strings.Index(text, "  # "); i >= 0 {
+		node.Description = strings.TrimSpace(text[i+4:])
+		text = text[:i]
+	}
+	text = strings.TrimSpace(text)
+
+	// Unreadable directories and links are marked after their name
+	for stripped := true; stripped; {
+		stripped = false
+		if strings.HasSuffix(text, " "+openErrorMark) {
+			node.IsDir = true
+			node.Err = errors.New(strings.Trim(openErrorMark, "[]"))
+			text, stripped = strings.TrimSpace(strings.TrimSuffix(text, openErrorMark)), true
+		}
+		if strings.HasSuffix(text, " "+brokenLinkMark) {
+			node.LinkBroken = true
+			text, stripped = strings.TrimSpace(strings.TrimSuffix(text, brokenLinkMark)), true
+		}
+		if strings.HasSuffix(text, " "+recursiveMark) {
+			node.Recursive = true
+			text, stripped = strings.TrimSpace(strings.TrimSuffix(text, recursiveMark)), true
+		}
+	}
+
+	text = stripInfoColumns(text)
+
+	// Strip a leading icon added by -C
+	if icon, rest, ok := strings.Cut(text, " "); ok && isFileIcon(icon) {
+		text = strings.TrimSpace(rest)
+	}
+
+	if strings.HasSuffix(text, "/") && text != "/" {
+		node.IsDir = true
+		text = strings.TrimSuffix(text, "/")
+	}
+	if name, target, ok := strings.Cut(text, " -> "); ok {
+		text, node.LinkTarget = name, target
+	}
+	if strings.HasPrefix(text, elidedPrefix) {
+		node.Elided = parseElidedCount(text)
+	}
+	node.Name = text
+	return node
+}
+
+// Strip the "[drwxr-xr-x  1.5K]  " columns added before entries by -s, -p
+// and the like
+func stripInfoColumns(text string) string {
+	if strings.HasPrefix(text, "[") {
+		if i := strings.Index(text, "]  "); i >= 0 {
+			return strings.TrimSpace(text[i+3:])
+		}
+	}
+	return text
+}
+
+// Set the depth of every node below t
+func setDepth(t *TreeNode, depth int) {
+	t.Depth = depth
+	for _, child := range t.Children {
+		setDepth(child, depth+1)
+	}
+}
@@ -0,0 +1,135 @@
+package treex
+
+import (
+	"testing"
+)
+
+func TestParseTreeListing(t *testing.T) {
+	listing := "```text\n" + `root/
+├── dir1/        # First directory
+│   └── file2.go
+└── file1.txt
+` + "```\n"
+
+	root, err := parseTreeListing(listing)
+	if err != nil {
+		t.Fatalf("parseTreeListing error: %v", err)
+	}
+
+	expected := createTestTree()
+	expected.Children[0].Description = "First directory"
+	if !equalTrees(root, expected) {
+		t.Errorf("Parsed tree doesn't match, got:\n%s", root.ToTreeString(true, "", false))
+	}
+}
+
+func TestParseTreeListingFormats(t *testing.T) {
+	testCases := []struct {
+		name    string
+		listing string
+	}{
+		{"tree", createTestTree().ToTreeString(true, "", false)},
+		{"tree with icons", createTestTree().ToTreeString(true, "", true)},
+		{"indent", createTestTree().ToIndentString(2, false)},
+		{"tree command", "root\n├── dir1\n│   └── file2.go\n└── file1.txt\n"},
+		{"ascii", "root/\n|-- dir1/\n|   `-- file2.go\n`-- file1.txt\n"},
+		{"tabs", "root/\n\tdir1/\n\t\tfile2.go\n\tfile1.txt\n"},
+	}
+
+	for _, tc := range testCases {
+		root, err := parseTreeListing(tc.listing)
+		if err != nil {
+			t.Errorf("%s: parseTreeListing error: %v", tc.name, err)
+			continue
+		}
+		if !equalTrees(root, createTestTree()) {
+			t.Errorf("%s: parsed tree doesn't match, got:\n%s", tc.name, root.ToTreeString(true, "", false))
+		}
+	}
+}
+
+func TestParseTreeListingRoot(t *testing.T) {
+	// Several top-level entries are placed under a "." root
+	root, err := parseTreeListing("cmd/\n  main.go\ngo.mod\n")
+	if err != nil {
+		t.Fatalf("parseTreeListing error: %v", err)
+	}
+	if root.Name != "." || len(root.Children) != 2 || root.Children[0].Children[0].Depth != 2 {
+		t.Errorf("Unexpected root, got:\n%s", root.ToTreeString(true, "", false))
+	}
+
+	if _, err := parseTreeListing("\n\n"); err == nil {
+		t.Error("Should fail on an empty listing")
+	}
+}
+
+func TestParseNamesWithSpaces(t *testing.T) {
+	// Only icons added by -C are stripped, not the first word of a name
+	tree := &TreeNode{Name: "文档", IsDir: true, Children: []*TreeNode{
+		{Name: "中文 文件.txt", Depth: 1},
+		{Name: "★ notes.md", Depth: 1},
+		{Name: "설정 파일", Depth: 1, IsDir: true},
+	}}
+	for _, icons := range []bool{false, true} {
+		root, err := parseTreeListing(tree.ToTreeString(true, "", icons))
+		if err != nil {
+			t.Fatalf("parseTreeListing error: %v", err)
+		}
+		if !equalTrees(root, tree) {
+			t.Errorf("Parsed tree doesn't match (icons %v), got:\n%s", icons, root.ToTreeString(true, "", false))
+		}
+	}
+}
+
+// Compare names, types, depths, descriptions and structure of two trees
+func equalTrees(a, b *TreeNode) bool {
+	if a.Name != b.Name || a.IsDir != b.IsDir || a.Depth != b.Depth || a.Description != b.Description {
+		return false
+	}
+	if len(a.Children) != len(b.Children) {
+		return false
+	}
+	for i := range a.Children {
+		if !equalTrees(a.Children[i], b.Children[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestParseMarkdownListing(t *testing.T) {
+	tree := createTestTree()
+	tree.Children[0].Description = "First directory"
+
+	testCases := []struct {
+		name    string
+		listing string
+	}{
+		{"md", tree.ToMarkdownString(0, false)},
+		{"md with icons", tree.ToMarkdownString(0, true)},
+		{"md with links", tree.ToMarkdownLinkString(0, false, tree.Name, "")},
+		{"md with links and icons", tree.ToMarkdownLinkString(0, true, tree.Name, "https://example.com")},
+	}
+
+	for _, tc := range testCases {
+		if !isMarkdownListing(tc.listing) {
+			t.Errorf("%s: listing should be detected as Markdown", tc.name)
+		}
+
+		root, err := ParseListing(tc.listing, "")
+		if err != nil {
+			t.Errorf("%s: ParseListing error: %v", tc.name, err)
+			continue
+		}
+		if !equalTrees(root, tree) {
+			t.Errorf("%s: parsed tree doesn't match, got:\n%s", tc.name, root.ToTreeString(true, "", false))
+		}
+	}
+
+	if isMarkdownListing(createTestTree().ToTreeString(true, "", false)) {
+		t.Error("Tree listing should not be detected as Markdown")
+	}
+	if _, err := parseMarkdownListing("- a/\n  not an item\n"); err == nil {
+		t.Error("Should fail on lines that aren't list items")
+	}
+}
@@ -0,0 +1,182 @@
+package treex
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// pathEntry is an entry of a flat path list, e.g. read from stdin
+type pathEntry struct {
+	Path    string // slash-separated, relative to the root
+	IsDir   bool
+	Size    int64
+	Mode    fs.FileMode
+	ModTime time.Time
+
+	LinkTarget string // target of a symbolic link
+	LinkBroken bool
+
+	Info fs.FileInfo // where the entry was read from, if anywhere
+}
+
+// ReadPathList reads a list of paths separated by NUL bytes (as printed by
+// `find -print0` or `fd -0`) if there are any, and by newlines otherwise.
+func ReadPathList(r io.Reader) ([]string, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	separator := "\n"
+	if bytes.IndexByte(content, 0) >= 0 {
+		separator = "\x00"
+	}
+
+	var paths []string
+	for _, p := range strings.Split(string(content), separator) {
+		p = strings.TrimRight(p, "\r")
+		if strings.TrimSpace(p) != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths, nil
+}
+
+// Turn paths relative to the working directory into entries, looking up the
+// type, size and link target of those that exist. A trailing "/" marks a
+// directory.
+func getPathEntries(paths []string) []pathEntry {
+	entries := make([]pathEntry, 0, len(paths))
+	for _, p := range paths {
+		entry := pathEntry{
+			Path:  p,
+			IsDir: strings.HasSuffix(p, "/"),
+		}
+		if info, err := os.Lstat(p); err == nil {
+			entry.IsDir = entry.IsDir || info.IsDir()
+			entry.Mode = info.Mode()
+			entry.ModTime = info.ModTime()
+			entry.Info = info
+			if !info.IsDir() {
+				entry.Size = info.Size()
+			}
+			if info.Mode()&fs.ModeSymlink != 0 {
+				entry.LinkTarget, _ = os.Readlink(p)
+				_, err := os.Stat(p)
+				entry.LinkBroken = err != nil
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// Assemble a tree from a flat list of entries, creating intermediate
+// directories as needed. Filtering options behave as in getTreeNode and
+// children are sorted by name like os.ReadDir returns them. Directories
+// without an entry of their own get a default mode.
+func buildTreeFromPaths(entries []pathEntry, rootName string, opts *Options, filter *Filter) *TreeNode {
+	root := &TreeNode{
+		Name:  rootName,
+		IsDir: true,
+		Mode:  fs.ModeDir | 0755,
+	}
+	index := map[*TreeNode]map[string]*TreeNode{}
+	// Paths left out by filters, counted once however many entries they hold
+	filtered := map[string]bool{}
+	countFiltered := func(parent *TreeNode, relativePath string) {
+		if !filtered[relativePath] {
+			filtered[relativePath] = true
+			parent.Filtered++
+		}
+	}
+
+	for _, entry := range entries {
+		cleaned := path.Clean("/" + strings.TrimSuffix(entry.Path, "/"))
+		if cleaned == "/" {
+			continue
+		}
+		segments := strings.Split(strings.TrimPrefix(cleaned, "/"), "/")
+
+		parent := root
+		for i, segment := range segments {
+			isLast := i == len(segments)-1
+			isDir := !isLast || entry.IsDir
+			depth := i + 1
+			relativePath := strings.Join(segments[:i+1], "/")
+
+			if (opts.HideHidden && strings.HasPrefix(segment, ".")) || filter.ShouldExclude(segment, isDir, relativePath) {
+				countFiltered(parent, relativePath)
+				break
+			}
+
+			// Files hidden by max-depth or dirs-only still count towards sizes
+			if opts.MaxDepth > 0 && depth > opts.MaxDepth {
+				parent.Size += entry.Size
+				break
+			}
+			if opts.DirsOnly && !isDir {
+				countFiltered(parent, relativePath)
+				parent.Size += entry.Size
+				break
+			}
+
+			if index[parent] == nil {
+				index[parent] = map[string]*TreeNode{}
+			}
+			child, ok := index[parent][segment]
+			if !ok {
+				child = &TreeNode{
+					Name:  segment,
+					Depth: depth,
+					Mode:  fs.ModeDir | 0755,
+				}
+				index[parent][segment] = child
+				parent.Children = append(parent.Children, child)
+			}
+			if isDir {
+				child.IsDir = true
+			}
+			if isLast {
+				if !entry.IsDir {
+					child.Size = entry.Size
+				}
+				if entry.Mode != 0 || !entry.IsDir {
+					child.Mode = entry.Mode
+				}
+				child.ModTime = entry.ModTime
+				child.LinkTarget, child.LinkBroken = entry.LinkTarget, entry.LinkBroken
+				if opts.Metadata && entry.Info != nil {
+					child.setMetadata(entry.Info)
+				}
+			}
+			parent = child
+		}
+	}
+
+	// With the "none" sort order, entries keep the order of the list
+	finalizePathTree(root, opts.Sort != "none")
+	return root
+}
+
+// Sort children by name if sortByName is set and add up directory sizes
+func finalizePathTree(t *TreeNode, sortByName bool) {
+	if sortByName {
+		sort.Slice(t.Children, func(i, j int) bool {
+			return t.Children[i].Name < t.Children[j].Name
+		})
+	}
+
+	for _, child := range t.Children {
+		if child.IsDir {
+			finalizePathTree(child, sortByName)
+		}
+		t.Size += child.Size
+	}
+}
@@ -0,0 +1,125 @@
+package treex
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadPathList(t *testing.T) {
+	paths, err := ReadPathList(strings.NewReader("a.txt\r\nsrc/b.go\n\n"))
+	if err != nil {
+		t.Fatalf("ReadPathList error: %v", err)
+	}
+	if !reflect.DeepEqual(paths, []string{"a.txt", "src/b.go"}) {
+		t.Errorf("Unexpected newline separated paths: %q", paths)
+	}
+
+	// NUL separated paths may contain newlines
+	paths, err = ReadPathList(strings.NewReader("a.txt\x00odd\nname.go\x00"))
+	if err != nil {
+		t.Fatalf("ReadPathList error: %v", err)
+	}
+	if !reflect.DeepEqual(paths, []string{"a.txt", "odd\nname.go"}) {
+		t.Errorf("Unexpected NUL separated paths: %q", paths)
+	}
+}
+
+func TestBuildTreeFromPaths(t *testing.T) {
+	entries := []pathEntry{
+		{Path: "file1.txt", Size: 10},
+		{Path: "./dir1/file2.go", Size: 5},
+		{Path: "dir1/", IsDir: true},
+	}
+
+	root := buildTreeFromPaths(entries, "root", &Options{}, NewFilter("", false))
+	if !equalTrees(root, createTestTree()) {
+		t.Errorf("Tree doesn't match, got:\n%s", root.ToTreeString(true, "", false))
+	}
+	if root.Size != 15 || root.Children[0].Size != 5 {
+		t.Errorf("Expected sizes 15 and 5, but got %d and %d", root.Size, root.Children[0].Size)
+	}
+
+	// The "none" sort order keeps the order of the list
+	root = buildTreeFromPaths(entries, "root", &Options{Sort: "none"}, NewFilter("", false))
+	if root.Children[0].Name != "file1.txt" || root.Children[1].Name != "dir1" {
+		t.Errorf("Expected the order of the list, got:\n%s", root.ToTreeString(true, "", false))
+	}
+	root, err := Build(&Options{Paths: []string{"zeta.txt", "alpha/b", "alpha/a"}, Sort: "none"})
+	if err != nil {
+		t.Fatalf("Build error: %v", err)
+	}
+	if expected := "./\n├── zeta.txt\n└── alpha/\n    ├── b\n    └── a\n"; root.ToTreeString(true, "", false) != expected {
+		t.Errorf("Expected:\n%s\nBut got:\n%s", expected, root.ToTreeString(true, "", false))
+	}
+}
+
+func TestBuildTreeFromPathsFilters(t *testing.T) {
+	paths := []string{
+		"src/main.go",
+		"src/util/strings.go",
+		"src/.cache/x",
+		"build/app.js",
+		"debug.log",
+		"README.md",
+	}
+	var entries []pathEntry
+	for _, p := range paths {
+		entries = append(entries, pathEntry{Path: p})
+	}
+
+	testCases := []struct {
+		name       string
+		filter     *Filter
+		maxDepth   int
+		hideHidden bool
+		dirsOnly   bool
+		expected   string
+	}{
+		{
+			name:     "All",
+			filter:   NewFilter("", false),
+			expected: ".,README.md,build,build/app.js,debug.log,src,src/.cache,src/.cache/x,src/main.go,src/util,src/util/strings.go",
+		},
+		{
+			name:       "Exclude and hidden",
+			filter:     NewFilter("build/, .log", false),
+			hideHidden: true,
+			expected:   ".,README.md,src,src/main.go,src/util,src/util/strings.go",
+		},
+		{
+			name:     "Max depth",
+			filter:   NewFilter("", false),
+			maxDepth: 1,
+			expected: ".,README.md,build,debug.log,src",
+		},
+		{
+			name:     "Dirs only",
+			filter:   NewFilter("", false),
+			dirsOnly: true,
+			expected: ".,build,src,src/.cache,src/util",
+		},
+	}
+
+	for _, tc := range testCases {
+		root := buildTreeFromPaths(entries, ".", &Options{MaxDepth: tc.maxDepth, HideHidden: tc.hideHidden, DirsOnly: tc.dirsOnly}, tc.filter)
+
+		var got []string
+		var collect func(node *TreeNode, nodePath string)
+		collect = func(node *TreeNode, nodePath string) {
+			got = append(got, nodePath)
+			for _, child := range node.Children {
+				childPath := child.Name
+				if nodePath != "." {
+					childPath = nodePath + "/" + child.Name
+				}
+				collect(child, childPath)
+			}
+		}
+		collect(root, ".")
+
+		if strings.Join(got, ",") != tc.expected {
+			t.Errorf("%s: expected %s, but got %s", tc.name, tc.expected, strings.Join(got, ","))
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package treex
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Renderer writes a tree to w in one output format. Renderers write as they
+// go rather than building the whole output in memory.
+type Renderer interface {
+	Render(w io.Writer, root *TreeNode) error
+}
+
+// RenderOptions holds the options shared by all output formats. Formats
+// ignore the options that don't apply to them.
+type RenderOptions struct {
+	Icons     bool         // prefix entries with file type icons
+	Sizes     bool         // show human-readable sizes before entries
+	Perms     bool         // show permissions before entries, e.g. "drwxr-xr-x"
+	Owner     bool         // show owner names before entries (needs Options.Metadata)
+	Group     bool         // show group names before entries (needs Options.Metadata)
+	Inode     bool         // show inode numbers before entries (needs Options.Metadata)
+	HardLinks bool         // show hard link counts before entries (needs Options.Metadata)
+	Links     bool         // link every entry to its path (md)
+	BaseURL   string       // URL prefix for links
+	Template  string       // Go text/template source (template)
+	Report    bool         // end with a summary of counts and sizes
+	Colors    *ColorScheme // color entries and connectors with ANSI escapes (tree, indent)
+}
+
+// Format is an output format that can be selected by name
+type Format struct {
+	Name        string
+	Description string
+	New         func(opts RenderOptions) (Renderer, error)
+}
+
+var (
+	formatsMu sync.RWMutex
+	formats   []Format
+)
+
+func init() {
+	RegisterFormat(Format{"indent", "indented list", func(opts RenderOptions) (Renderer, error) {
+		return IndentRenderer{RenderOptions: opts}, nil
+	}})
+	RegisterFormat(Format{"tree", "box-drawing tree like the tree command", func(opts RenderOptions) (Renderer, error) {
+		return TreeRenderer{opts}, nil
+	}})
+	RegisterFormat(Format{"md", "nested Markdown list", func(opts RenderOptions) (Renderer, error) {
+		return MarkdownRenderer{opts}, nil
+	}})
+	RegisterFormat(Format{"md-code", "tree in a fenced Markdown code block", func(opts RenderOptions) (Renderer, error) {
+		return MarkdownCodeRenderer{opts}, nil
+	}})
+	RegisterFormat(Format{"md-table", "Markdown table with path, type, size and description", func(opts RenderOptions) (Renderer, error) {
+		return MarkdownTableRenderer{opts}, nil
+	}})
+	RegisterFormat(Format{"mermaid", "Mermaid flowchart", func(opts RenderOptions) (Renderer, error) {
+		return MermaidRenderer{Report: opts.Report}, nil
+	}})
+	RegisterFormat(Format{"json", "indented JSON document", func(opts RenderOptions) (Renderer, error) {
+		return JSONRenderer{Report: opts.Report}, nil
+	}})
+	RegisterFormat(Format{"treemap-svg", "SVG treemap sized by file size", func(opts RenderOptions) (Renderer, error) {
+		return TreemapSVGRenderer{Report: opts.Report}, nil
+	}})
+	RegisterFormat(Format{"template", "custom Go text/template (see --template)", func(opts RenderOptions) (Renderer, error) {
+		if opts.Template == "" {
+			return nil, fmt.Errorf("the template format requires a template")
+		}
+		return TemplateRenderer{Template: opts.Template}, nil
+	}})
+}
+
+// RegisterFormat makes a format available to NewRenderer. Like
+// database/sql.Register, it panics if the name is empty or already taken.
+func RegisterFormat(f Format) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	if f.Name == "" || f.New == nil {
+		panic("treex: RegisterFormat needs a name and a constructor")
+	}
+	for _, existing := range formats {
+		if existing.Name == f.Name {
+			panic("treex: RegisterFormat called twice for format " + f.Name)
+		}
+	}
+	formats = append(formats, f)
+}
+
+// Formats returns the registered formats in registration order
+func Formats() []Format {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	return append([]Format(nil), formats...)
+}
+
+// FormatNames returns the names of the registered formats
+func FormatNames() []string {
+	var names []string
+	for _, f := range Formats() {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// NewRenderer creates a renderer for the named format
+func NewRenderer(name string, opts RenderOptions) (Renderer, error) {
+	for _, f := range Formats() {
+		if f.Name == name {
+			return f.New(opts)
+		}
+	}
+	return nil, fmt.Errorf("unknown outputFormat '%s'", name)
+}
+
+// TreeRenderer draws the tree with box-drawing connectors like `tree`
+type TreeRenderer struct {
+	RenderOptions
+}
+
+func (r TreeRenderer) Render(w io.Writer, root *TreeNode) error {
+	ew := &errWriter{w: w}
+	root.writeTree(ew, true, "", r.RenderOptions, root.getDescriptionColumn(4, r.RenderOptions))
+	if r.Report {
+		root.writeReport(ew)
+	}
+	return ew.err
+}
+
+// IndentRenderer indents every level by Spaces spaces (4 if unset)
+type IndentRenderer struct {
+	RenderOptions
+	Spaces int
+}
+
+func (r IndentRenderer) Render(w io.Writer, root *TreeNode) error {
+	spaces := r.Spaces
+	if spaces <= 0 {
+		spaces = 4
+	}
+	ew := &errWriter{w: w}
+	root.writeIndent(ew, spaces, r.RenderOptions, root.getDescriptionColumn(spaces, r.RenderOptions))
+	if r.Report {
+		root.writeReport(ew)
+	}
+	return ew.err
+}
+
+// MarkdownRenderer renders a nested Markdown list, linking every entry to its
+// path prefixed with BaseURL if Links is set
+type MarkdownRenderer struct {
+	RenderOptions
+}
+
+func (r MarkdownRenderer) Render(w io.Writer, root *TreeNode) error {
+	ew := &errWriter{w: w}
+	opts := r.RenderOptions
+	opts.Colors = nil
+	if r.Links {
+		root.writeMarkdownLinks(ew, 0, opts, root.Name)
+	} else {
+		root.writeMarkdown(ew, 0, opts)
+	}
+	if r.Report {
+		root.writeReport(ew)
+	}
+	return ew.err
+}
+
+// MarkdownCodeRenderer renders the tree format inside a fenced code block
+type MarkdownCodeRenderer struct {
+	RenderOptions
+}
+
+func (r MarkdownCodeRenderer) Render(w io.Writer, root *TreeNode) error {
+	ew := &errWriter{w: w}
+	root.writeMarkdownCode(ew, r.RenderOptions)
+	return ew.err
+}
+
+// MarkdownTableRenderer renders a Markdown table with one row per entry
+type MarkdownTableRenderer struct {
+	RenderOptions
+}
+
+func (r MarkdownTableRenderer) Render(w io.Writer, root *TreeNode) error {
+	ew := &errWriter{w: w}
+	root.writeMarkdownTable(ew, r.RenderOptions)
+	if r.Report {
+		root.writeReport(ew)
+	}
+	return ew.err
+}
+
+// MermaidRenderer renders a Mermaid flowchart, with the report as a comment
+// if Report is set
+type MermaidRenderer struct {
+	Report bool
+}
+
+func (r MermaidRenderer) Render(w io.Writer, root *TreeNode) error {
+	ew := &errWriter{w: w}
+	root.writeMermaid(ew)
+	if r.Report {
+		io.WriteString(ew, "    %% "+root.Report().String()+"\n")
+	}
+	return ew.err
+}
+
+// JSONRenderer renders an indented JSON document, with a "report" object in
+// the root if Report is set
+type JSONRenderer struct {
+	Report bool
+}
+
+func (r JSONRenderer) Render(w io.Writer, root *TreeNode) error {
+	ew := &errWriter{w: w}
+	var report *Report
+	if r.Report {
+		rootReport := root.Report()
+		report = &rootReport
+	}
+	root.writeJSON(ew, "", report)
+	io.WriteString(ew, "\n")
+	return ew.err
+}
+
+// TreemapSVGRenderer renders an SVG treemap, 1200x800 unless a size is set,
+// with the report as its description if Report is set
+type TreemapSVGRenderer struct {
+	Width  int
+	Height int
+	Report bool
+}
+
+func (r TreemapSVGRenderer) Render(w io.Writer, root *TreeNode) error {
+	width, height := r.Width, r.Height
+	if width <= 0 || height <= 0 {
+		width, height = treemapWidth, treemapHeight
+	}
+	ew := &errWriter{w: w}
+	var desc string
+	if r.Report {
+		desc = root.Report().String()
+	}
+	root.writeTreemapSVG(ew, width, height, desc)
+	return ew.err
+}
+
+// TemplateRenderer executes a Go text/template, see ToTemplateString
+type TemplateRenderer struct {
+	Template string
+}
+
+func (r TemplateRenderer) Render(w io.Writer, root *TreeNode) error {
+	if err := root.writeTemplate(w, r.Template); err != nil {
+		return fmt.Errorf("executing template: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package treex
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestNewRenderer(t *testing.T) {
+	tree := createTestTree()
+
+	testCases := []struct {
+		format   string
+		opts     RenderOptions
+		expected string
+	}{
+		{"tree", RenderOptions{}, tree.ToTreeString(true, "", false)},
+		{"indent", RenderOptions{}, tree.ToIndentString(4, false)},
+		{"indent", RenderOptions{Icons: true}, tree.ToIndentString(4, true)},
+		{"md", RenderOptions{}, tree.ToMarkdownString(0, false)},
+		{"md", RenderOptions{Links: true, BaseURL: "https://x/"}, tree.ToMarkdownLinkString(0, false, "root", "https://x/")},
+		{"md-code", RenderOptions{}, tree.ToMarkdownCodeString(false)},
+		{"md-table", RenderOptions{}, tree.ToMarkdownTableString(false)},
+		{"mermaid", RenderOptions{}, tree.ToMermaidString()},
+		{"json", RenderOptions{}, tree.ToJSONString()},
+		{"treemap-svg", RenderOptions{}, tree.ToTreemapSVG(treemapWidth, treemapHeight)},
+		{"template", RenderOptions{Template: "{{.Root.Name}}"}, "root"},
+	}
+
+	for _, tc := range testCases {
+		renderer, err := NewRenderer(tc.format, tc.opts)
+		if err != nil {
+			t.Errorf("%s: NewRenderer error: %v", tc.format, err)
+			continue
+		}
+		var result strings.Builder
+		if err := renderer.Render(&result, tree); err != nil {
+			t.Errorf("%s: Render error: %v", tc.format, err)
+			continue
+		}
+		if result.String() != tc.expected {
+			t.Errorf("%s: Expected:\n%s\nBut got:\n%s", tc.format, tc.expected, result.String())
+		}
+
+		// Write errors are reported
+		if err := renderer.Render(failingWriter{}, tree); err == nil {
+			t.Errorf("%s: Expected a write error", tc.format)
+		}
+	}
+
+	if _, err := NewRenderer("xml", RenderOptions{}); err == nil {
+		t.Error("Should fail on unknown formats")
+	}
+	if _, err := NewRenderer("template", RenderOptions{}); err == nil {
+		t.Error("The template format should require a template")
+	}
+
+	err := TemplateRenderer{Template: "{{.Missing}}"}.Render(io.Discard, tree)
+	if err == nil || !strings.HasPrefix(err.Error(), "executing template:") {
+		t.Errorf("Expected a template execution error, but got %v", err)
+	}
+}
+
+func TestRenderSizes(t *testing.T) {
+	tree := createTestTree()
+	tree.Size, tree.Children[0].Size, tree.Children[1].Size = 1536, 1024, 512
+	tree.Children[0].Children[0].Size = 1024
+
+	renderer, _ := NewRenderer("tree", RenderOptions{Sizes: true})
+	var result strings.Builder
+	renderer.Render(&result, tree)
+	expected := `[ 1.5K]  root/
+├── [ 1.0K]  dir1/
+│   └── [ 1.0K]  file2.go
+└── [ 512B]  file1.txt
+`
+	if result.String() != expected {
+		t.Errorf("Expected:\n%s\nBut got:\n%s", expected, result.String())
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+type upperRenderer struct{}
+
+func (upperRenderer) Render(w io.Writer, root *TreeNode) error {
+	_, err := io.WriteString(w, strings.ToUpper(root.Name)+"\n")
+	return err
+}
+
+func TestRegisterFormat(t *testing.T) {
+	saved := Formats()
+	t.Cleanup(func() {
+		formats = saved
+	})
+
+	RegisterFormat(Format{"upper-test", "root name in capitals", func(opts RenderOptions) (Renderer, error) {
+		return upperRenderer{}, nil
+	}})
+
+	names := FormatNames()
+	if names[len(names)-1] != "upper-test" {
+		t.Errorf("Registered formats should be listed last, got %v", names)
+	}
+
+	renderer, err := NewRenderer("upper-test", RenderOptions{})
+	if err != nil {
+		t.Fatalf("NewRenderer error: %v", err)
+	}
+	var result strings.Builder
+	renderer.Render(&result, createTestTree())
+	if result.String() != "ROOT\n" {
+		t.Errorf("Unexpected output %q", result.String())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Registering a format twice should panic")
+		}
+	}()
+	RegisterFormat(Format{"tree", "", func(opts RenderOptions) (Renderer, error) { return nil, nil }})
+}
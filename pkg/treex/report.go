@@ -0,0 +1,69 @@
+package treex
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Report summarizes a tree, like the last line printed by `tree`
+type Report struct {
+	Directories int   `json:"directories"` // not counting the root
+	Files       int   `json:"files"`
+	Size        int64 `json:"size"`     // total size in bytes
+	Filtered    int   `json:"filtered"` // entries left out by the hidden, exclude and dirs-only filters
+	Errors      int   `json:"errors"`   // directories that couldn't be read
+	Elided      int   `json:"elided"`   // entries left out by the entry limits
+}
+
+// Report counts the entries of the tree
+func (t *TreeNode) Report() Report {
+	report := Report{Size: t.Size}
+	for _, node := range t.getAllNodes() {
+		switch {
+		case node == t:
+		case node.Elided > 0:
+			report.Elided += node.Elided
+		case node.IsDir:
+			// Compacted chains like "src/main/java" count every directory
+			report.Directories += 1 + strings.Count(node.Name, "/")
+		default:
+			report.Files++
+		}
+		report.Filtered += node.Filtered
+		if node.Err != nil {
+			report.Errors++
+		}
+	}
+	return report
+}
+
+// String formats the report as a single line, e.g.
+// "3 directories, 12 files, 48.2K total, 2 filtered out"
+func (r Report) String() string {
+	s := fmt.Sprintf("%s, %s, %s total", pluralize(r.Directories, "directory", "directories"), pluralize(r.Files, "file", "files"), formatSize(r.Size))
+	if r.Filtered > 0 {
+		s += fmt.Sprintf(", %d filtered out", r.Filtered)
+	}
+	if r.Elided > 0 {
+		s += fmt.Sprintf(", %d elided", r.Elided)
+	}
+	if r.Errors > 0 {
+		s += ", " + pluralize(r.Errors, "error", "errors")
+	}
+	return s
+}
+
+func pluralize(count int, singular, plural string) string {
+	if count == 1 {
+		return "1 " + singular
+	}
+	return fmt.Sprintf("%d %s", count, plural)
+}
+
+// Matches report lines, so listings with a report can be parsed back
+var reportLinePattern = regexp.MustCompile(`^\d+ director(y|ies), \d+ files?(,|$)`)
+
+func isReportLine(line string) bool {
+	return reportLinePattern.MatchString(line)
+}
@@ -0,0 +1,95 @@
+package treex
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestReport(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a/b/c.go":  {Data: make([]byte, 1000)},
+		"a/d.log":   {Data: make([]byte, 24)},
+		".hidden/x": {Data: make([]byte, 10)},
+		"e.txt":     {Data: make([]byte, 2048)},
+	}
+	failing := failingFS{fsys, map[string]bool{"a/b": true}}
+
+	node, err := walkTree(failing, "root", &Options{HideHidden: true}, NewFilter(".log", false))
+	if err != nil {
+		t.Fatalf("walkTree error: %v", err)
+	}
+
+	report := node.Report()
+	expected := Report{Directories: 2, Files: 1, Size: 2048, Filtered: 2, Errors: 1}
+	if report != expected {
+		t.Errorf("Expected %+v, but got %+v", expected, report)
+	}
+	if s := report.String(); s != "2 directories, 1 file, 2.0K total, 2 filtered out, 1 error" {
+		t.Errorf("Unexpected report %q", s)
+	}
+
+	// Files hidden by dirs-only are filtered out too
+	node, _ = walkTree(fsys, "root", &Options{DirsOnly: true}, NewFilter("", false))
+	if report := node.Report(); report.Files != 0 || report.Filtered != 4 || report.Directories != 3 {
+		t.Errorf("Unexpected dirs-only report %+v", report)
+	}
+
+	// Entries of path lists are counted once per filtered path
+	root := buildTreeFromPaths([]pathEntry{{Path: "x/a.log"}, {Path: "x/b.log"}, {Path: "y/"}, {Path: "y/.git/HEAD"}, {Path: "y/.git/config"}}, ".", &Options{HideHidden: true}, NewFilter(".log", false))
+	if report := root.Report(); report.Filtered != 3 || report.Directories != 2 || report.Files != 0 {
+		t.Errorf("Unexpected path list report %+v", report)
+	}
+}
+
+func TestRenderReport(t *testing.T) {
+	tree := createTestTree()
+	line := tree.Report().String()
+
+	testCases := []struct {
+		format string
+		suffix string
+	}{
+		{"tree", "└── file1.txt\n\n" + line + "\n"},
+		{"indent", "file1.txt\n\n" + line + "\n"},
+		{"md", "- file1.txt\n\n" + line + "\n"},
+		{"md-code", "└── file1.txt\n\n" + line + "\n```\n"},
+		{"md-table", "| |\n\n" + line + "\n"},
+		{"mermaid", "    %% " + line + "\n"},
+		{"json", "\n  ],\n  \"report\": {\n    \"directories\": 1,\n    \"files\": 2,\n    \"size\": 0,\n    \"filtered\": 0,\n    \"errors\": 0,\n    \"elided\": 0\n  }\n}\n"},
+	}
+
+	for _, tc := range testCases {
+		renderer, _ := NewRenderer(tc.format, RenderOptions{Report: true})
+		var result strings.Builder
+		renderer.Render(&result, tree)
+		if !strings.HasSuffix(result.String(), tc.suffix) {
+			t.Errorf("%s: expected the output to end with:\n%s\nBut got:\n%s", tc.format, tc.suffix, result.String())
+		}
+
+		// Listings with a report can be parsed back
+		if tc.format == "tree" || tc.format == "md" || tc.format == "json" {
+			parsed, err := ParseListing(result.String(), tc.format)
+			if err != nil || !equalTrees(parsed, tree) {
+				t.Errorf("%s: listing with a report doesn't parse back: %v", tc.format, err)
+			}
+		}
+	}
+
+	renderer, _ := NewRenderer("treemap-svg", RenderOptions{Report: true})
+	var result strings.Builder
+	renderer.Render(&result, tree)
+	if !strings.Contains(result.String(), "<desc>"+line+"</desc>") {
+		t.Errorf("treemap-svg: expected a description, got:\n%s", result.String())
+	}
+
+	// The JSON report decodes as part of the root object
+	renderer, _ = NewRenderer("json", RenderOptions{Report: true})
+	result.Reset()
+	renderer.Render(&result, tree)
+	var decoded jsonNode
+	if err := json.Unmarshal([]byte(result.String()), &decoded); err != nil || decoded.Report == nil || decoded.Report.Files != 2 {
+		t.Errorf("Unexpected JSON report %+v, error %v", decoded.Report, err)
+	}
+}
@@ -0,0 +1,172 @@
+package treex
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// SortOrders lists the values accepted by Options.Sort
+var SortOrders = []string{"name", "natural", "size", "mtime", "ext", "none"}
+
+// Check the sorting options before walking anything
+func validateSortOptions(opts *Options) error {
+	if opts.DirsFirst && opts.FilesFirst {
+		return fmt.Errorf("dirs-first and files-first can't be combined")
+	}
+	if opts.Sort == "" {
+		return nil
+	}
+	for _, order := range SortOrders {
+		if opts.Sort == order {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown sort order '%s'", opts.Sort)
+}
+
+// Sort the children of every directory of t as described by opts
+func sortTree(t *TreeNode, opts *Options) {
+	less := getSortFunc(opts.Sort, opts.IgnoreCase)
+	if opts.Reverse && less != nil {
+		forward := less
+		less = func(a, b *TreeNode) bool {
+			return forward(b, a)
+		}
+	}
+	sortChildren(t, less, opts)
+}
+
+func sortChildren(t *TreeNode, less func(a, b *TreeNode) bool, opts *Options) {
+	children := t.Children
+	switch {
+	case less != nil:
+		sort.SliceStable(children, func(i, j int) bool {
+			return less(children[i], children[j])
+		})
+	case opts.Reverse:
+		for i, j := 0, len(children)-1; i < j; i, j = i+1, j-1 {
+			children[i], children[j] = children[j], children[i]
+		}
+	}
+
+	// Grouping keeps the order within directories and files
+	if opts.DirsFirst || opts.FilesFirst {
+		sort.SliceStable(children, func(i, j int) bool {
+			return children[i].IsDir == opts.DirsFirst && children[j].IsDir != opts.DirsFirst
+		})
+	}
+
+	for _, child := range children {
+		sortChildren(child, less, opts)
+	}
+}
+
+// Get the comparison function of a sort order, nil to keep the order in
+// which entries were read
+func getSortFunc(order string, ignoreCase bool) func(a, b *TreeNode) bool {
+	byName := func(a, b *TreeNode) bool {
+		return compareNames(a.Name, b.Name, ignoreCase) < 0
+	}
+
+	switch order {
+	case "natural":
+		return func(a, b *TreeNode) bool {
+			if c := compareNatural(foldCase(a.Name, ignoreCase), foldCase(b.Name, ignoreCase)); c != 0 {
+				return c < 0
+			}
+			return a.Name < b.Name
+		}
+	case "size":
+		// Largest first, like `tree --sort=size`
+		return func(a, b *TreeNode) bool {
+			if a.Size != b.Size {
+				return a.Size > b.Size
+			}
+			return byName(a, b)
+		}
+	case "mtime":
+		// Oldest first, like `tree --sort=mtime`
+		return func(a, b *TreeNode) bool {
+			if !a.ModTime.Equal(b.ModTime) {
+				return a.ModTime.Before(b.ModTime)
+			}
+			return byName(a, b)
+		}
+	case "ext":
+		return func(a, b *TreeNode) bool {
+			if c := compareNames(getSortExtension(a), getSortExtension(b), ignoreCase); c != 0 {
+				return c < 0
+			}
+			return byName(a, b)
+		}
+	case "none":
+		return nil
+	default:
+		return byName
+	}
+}
+
+// Compare names byte-wise, optionally ignoring case first
+func compareNames(a, b string, ignoreCase bool) int {
+	if ignoreCase {
+		if c := strings.Compare(strings.ToLower(a), strings.ToLower(b)); c != 0 {
+			return c
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+func foldCase(s string, ignoreCase bool) string {
+	if ignoreCase {
+		return strings.ToLower(s)
+	}
+	return s
+}
+
+// Compare strings with runs of digits compared by their numeric value, so
+// "file2" sorts before "file10"
+func compareNatural(a, b string) int {
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			numA, restA := splitDigits(a)
+			numB, restB := splitDigits(b)
+			// Compare without leading zeros: longer numbers are larger
+			trimmedA, trimmedB := strings.TrimLeft(numA, "0"), strings.TrimLeft(numB, "0")
+			if len(trimmedA) != len(trimmedB) {
+				return len(trimmedA) - len(trimmedB)
+			}
+			if c := strings.Compare(trimmedA, trimmedB); c != 0 {
+				return c
+			}
+			a, b = restA, restB
+			continue
+		}
+		if a[0] != b[0] {
+			return int(a[0]) - int(b[0])
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) - len(b)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func splitDigits(s string) (string, string) {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+// Get the extension a node is sorted by; directories have none
+func getSortExtension(t *TreeNode) string {
+	if t.IsDir {
+		return ""
+	}
+	return path.Ext(t.Name)
+}
@@ -0,0 +1,91 @@
+package treex
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func createSortTestTree() *TreeNode {
+	day := func(d int) time.Time {
+		return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	return &TreeNode{Name: "root", IsDir: true, Children: []*TreeNode{
+		{Name: "B", IsDir: true, Size: 10, ModTime: day(3)},
+		{Name: "a", IsDir: true, Size: 5, ModTime: day(5)},
+		{Name: "file10.go", Size: 2, ModTime: day(1)},
+		{Name: "file9.go", Size: 1, ModTime: day(2)},
+		{Name: "Y.md", Size: 40, ModTime: day(4)},
+		{Name: "z.txt", Size: 7, ModTime: day(6)},
+	}}
+}
+
+func getChildNames(t *TreeNode) string {
+	var names []string
+	for _, child := range t.Children {
+		names = append(names, child.Name)
+	}
+	return strings.Join(names, " ")
+}
+
+func TestSortTree(t *testing.T) {
+	testCases := []struct {
+		opts     Options
+		expected string
+	}{
+		{Options{}, "B Y.md a file10.go file9.go z.txt"},
+		{Options{Sort: "name", IgnoreCase: true}, "a B file10.go file9.go Y.md z.txt"},
+		{Options{Sort: "natural"}, "B Y.md a file9.go file10.go z.txt"},
+		{Options{Sort: "size"}, "Y.md B z.txt a file10.go file9.go"},
+		{Options{Sort: "mtime"}, "file10.go file9.go B Y.md a z.txt"},
+		{Options{Sort: "ext"}, "B a file10.go file9.go Y.md z.txt"},
+		{Options{Sort: "none"}, "B a file10.go file9.go Y.md z.txt"},
+		{Options{Sort: "none", Reverse: true}, "z.txt Y.md file9.go file10.go a B"},
+		{Options{Sort: "size", Reverse: true}, "file9.go file10.go a z.txt B Y.md"},
+		{Options{Sort: "natural", FilesFirst: true}, "Y.md file9.go file10.go z.txt B a"},
+		{Options{Sort: "mtime", Reverse: true, DirsFirst: true}, "a B z.txt Y.md file9.go file10.go"},
+	}
+
+	for _, tc := range testCases {
+		tree := createSortTestTree()
+		sortTree(tree, &tc.opts)
+		if result := getChildNames(tree); result != tc.expected {
+			t.Errorf("%+v: expected %q, but got %q", tc.opts, tc.expected, result)
+		}
+	}
+}
+
+func TestValidateSortOptions(t *testing.T) {
+	for _, order := range append(SortOrders, "") {
+		if err := validateSortOptions(&Options{Sort: order}); err != nil {
+			t.Errorf("%q: unexpected error %v", order, err)
+		}
+	}
+	if err := validateSortOptions(&Options{Sort: "color"}); err == nil {
+		t.Error("Should fail on unknown sort orders")
+	}
+	if err := validateSortOptions(&Options{DirsFirst: true, FilesFirst: true}); err == nil {
+		t.Error("Should fail when both dirs-first and files-first are set")
+	}
+}
+
+func TestCompareNatural(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"file2", "file10", -1},
+		{"file10", "file2", 1},
+		{"file02", "file2", 0},
+		{"v1.10.0", "v1.9.3", 1},
+		{"a", "ab", -1},
+		{"img12b", "img12a", 1},
+	}
+
+	for _, tc := range testCases {
+		result := compareNatural(tc.a, tc.b)
+		if (result < 0 && tc.expected >= 0) || (result > 0 && tc.expected <= 0) || (result == 0 && tc.expected != 0) {
+			t.Errorf("compareNatural(%q, %q) = %d, expected the sign of %d", tc.a, tc.b, result, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package treex
+
+import "io/fs"
+
+// Owners, inodes and link counts are only read on Unix
+func getFileStat(info fs.FileInfo) (fileStat, bool) {
+	return fileStat{}, false
+}
@@ -0,0 +1,22 @@
+//go:build unix
+
+package treex
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// Get the Unix stat data of info, if it comes from the OS
+func getFileStat(info fs.FileInfo) (fileStat, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileStat{}, false
+	}
+	return fileStat{
+		uid:   uint64(st.Uid),
+		gid:   uint64(st.Gid),
+		inode: uint64(st.Ino),
+		links: uint64(st.Nlink),
+	}, true
+}
@@ -0,0 +1,96 @@
+package treex
+
+import (
+	"io"
+	"path"
+	"strings"
+	"text/template"
+)
+
+// TemplateEntry is a TreeNode as seen by a --template file, along with the
+// layout information the built-in tree format computes while rendering.
+type TemplateEntry struct {
+	*TreeNode
+	Path   string // slash-separated path, starting with the root's name
+	IsLast bool   // whether this is the last child of its parent
+	Prefix string // connector prefix as printed by the tree format, e.g. "│   └── "
+}
+
+// TemplateData is the value passed to a --template file
+type TemplateData struct {
+	Root    *TreeNode
+	Entries []TemplateEntry // all nodes in display order, starting with the root
+	Report  Report
+}
+
+// Helper functions available in templates
+var templateFuncs = template.FuncMap{
+	"indent": func(depth int, width int) string {
+		return strings.Repeat(" ", depth*width)
+	},
+	"repeat": func(s string, count int) string {
+		return strings.Repeat(s, count)
+	},
+	"connector": func(isLast bool) string {
+		if isLast {
+			return "└── "
+		}
+		return "├── "
+	},
+	"icon": func(node *TreeNode) string {
+		return getFileIcon(node.Name, node.IsDir)
+	},
+	"entry": func(node *TreeNode, useIcons bool) string {
+		return node.getEntryString(RenderOptions{Icons: useIcons})
+	},
+	"size":  formatSize,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// ToTemplateString renders the tree with a user supplied Go text/template
+func (t *TreeNode) ToTemplateString(tmplText string) (string, error) {
+	var result strings.Builder
+	if err := t.writeTemplate(&result, tmplText); err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
+
+func (t *TreeNode) writeTemplate(w io.Writer, tmplText string) error {
+	tmpl, err := template.New("treex").Funcs(templateFuncs).Option("missingkey=error").Parse(tmplText)
+	if err != nil {
+		return err
+	}
+
+	data := TemplateData{Root: t, Report: t.Report()}
+	t.appendTemplateEntries(&data.Entries, true, "", t.Name)
+	return tmpl.Execute(w, data)
+}
+
+func (t *TreeNode) appendTemplateEntries(entries *[]TemplateEntry, isLast bool, prefix string, nodePath string) {
+	entry := TemplateEntry{
+		TreeNode: t,
+		Path:     nodePath,
+		IsLast:   isLast,
+		Prefix:   prefix,
+	}
+
+	// Same prefix rules as ToTreeString
+	childPrefix := ""
+	if t.Depth > 0 {
+		if isLast {
+			entry.Prefix += "└── "
+			childPrefix = prefix + "    "
+		} else {
+			entry.Prefix += "├── "
+			childPrefix = prefix + "│   "
+		}
+	}
+
+	*entries = append(*entries, entry)
+	for i, child := range t.Children {
+		isLastChild := i == len(t.Children)-1
+		child.appendTemplateEntries(entries, isLastChild, childPrefix, path.Join(nodePath, child.Name))
+	}
+}
@@ -0,0 +1,69 @@
+package treex
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToTemplateString(t *testing.T) {
+	tree := createTestTree()
+
+	tmpl := "{{range .Entries}}{{.Prefix}}{{entry .TreeNode false}} {{.Path}} {{.Depth}} {{.IsLast}}\n{{end}}"
+	result, err := tree.ToTemplateString(tmpl)
+	if err != nil {
+		t.Fatalf("ToTemplateString error: %v", err)
+	}
+
+	expectedLines := []string{
+		"root/ root 0 true",
+		"├── dir1/ root/dir1 1 false",
+		"│   └── file2.go root/dir1/file2.go 2 true",
+		"└── file1.txt root/file1.txt 1 true",
+	}
+
+	lines := strings.Split(strings.TrimSuffix(result, "\n"), "\n")
+	if len(lines) != len(expectedLines) {
+		t.Fatalf("Expected %d lines, but got %d:\n%s", len(expectedLines), len(lines), result)
+	}
+	for i, line := range expectedLines {
+		if lines[i] != line {
+			t.Errorf("Line %d: expected %q, but got %q", i, line, lines[i])
+		}
+	}
+
+	// The output should match the built-in tree format
+	result, err = tree.ToTemplateString("{{range .Entries}}{{.Prefix}}{{entry .TreeNode false}}\n{{end}}")
+	if err != nil {
+		t.Fatalf("ToTemplateString error: %v", err)
+	}
+	if result != tree.ToTreeString(true, "", false) {
+		t.Errorf("Template output should match tree output, got:\n%s", result)
+	}
+}
+
+func TestToTemplateStringRecursive(t *testing.T) {
+	tree := createTestTree()
+
+	tmpl := `{{define "node"}}{{indent .Depth 2}}{{.Name}}
+{{range .Children}}{{template "node" .}}{{end}}{{end}}{{template "node" .Root}}`
+	result, err := tree.ToTemplateString(tmpl)
+	if err != nil {
+		t.Fatalf("ToTemplateString error: %v", err)
+	}
+
+	expected := "root\n  dir1\n    file2.go\n  file1.txt\n"
+	if result != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result)
+	}
+}
+
+func TestToTemplateStringErrors(t *testing.T) {
+	tree := createTestTree()
+
+	if _, err := tree.ToTemplateString("{{range .Entries}"); err == nil {
+		t.Error("Should fail to parse an invalid template")
+	}
+	if _, err := tree.ToTemplateString("{{.Missing}}"); err == nil {
+		t.Error("Should fail on unknown fields")
+	}
+}
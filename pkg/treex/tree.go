@@ -0,0 +1,343 @@
+package treex
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+type TreeNode struct {
+	Name        string
+	IsDir       bool
+	Size        int64 // file size in bytes; for directories, the sum of their children
+	Description string
+	Mode        fs.FileMode
+	ModTime     time.Time // modification time, zero if unknown
+	Children    []*TreeNode
+	Depth       int
+	Err         error  // why a directory couldn't be read; its children are missing
+	LinkTarget  string // target of a symbolic link, as written in the link
+	LinkBroken  bool   // the target of the link doesn't exist
+	Recursive   bool   // a followed link to one of its parent directories, not descended into
+	Filtered    int    // children left out by the hidden, exclude and dirs-only filters
+	Elided      int    // for summary nodes like "… 12 more files", the number of entries left out by the limits
+	Owner       string // owner name (or ID without one); recorded with Options.Metadata
+	Group       string // group name (or ID without one); recorded with Options.Metadata
+	Inode       uint64 // recorded with Options.Metadata on Unix
+	HardLinks   uint64 // number of hard links; recorded with Options.Metadata on Unix
+}
+
+// Markers shown after entries, as printed by `tree`
+const (
+	openErrorMark  = "[error opening dir]"
+	brokenLinkMark = "[broken link]"
+	recursiveMark  = "[recursive, not followed]"
+)
+
+// Get the markers shown after the entry
+func (t *TreeNode) getMarks() []string {
+	var marks []string
+	if t.Err != nil {
+		marks = append(marks, openErrorMark)
+	}
+	if t.LinkBroken {
+		marks = append(marks, brokenLinkMark)
+	}
+	if t.Recursive {
+		marks = append(marks, recursiveMark)
+	}
+	return marks
+}
+
+// Errors returns the errors of the directories that couldn't be read, in
+// display order
+func (t *TreeNode) Errors() []error {
+	var errs []error
+	for _, node := range t.getAllNodes() {
+		if node.Err != nil {
+			errs = append(errs, node.Err)
+		}
+	}
+	return errs
+}
+
+func getRelativePath(absolute string, root string) string {
+	if !strings.HasSuffix(root, "/") {
+		root += "/"
+	}
+
+	if !strings.HasPrefix(absolute, root) {
+		return absolute
+	}
+
+	relativePath := strings.TrimPrefix(absolute, root)
+	relativePath = strings.TrimSuffix(relativePath, "/")
+
+	return relativePath
+}
+
+// treeWalker builds trees from a filesystem. Up to len(slots) subdirectories
+// are read by extra goroutines while the caller keeps walking.
+type treeWalker struct {
+	fsys   fs.FS
+	root   string // name of the root, which prefixes the paths passed to the filter
+	opts   *Options
+	filter *Filter
+	slots  chan struct{}
+}
+
+// ancestor is a directory above the one being read, used to detect followed
+// links that loop back. The list is shared read-only between goroutines.
+type ancestor struct {
+	info   fs.FileInfo
+	parent *ancestor
+}
+
+// Report whether info is the same directory as one of the ancestors.
+// os.SameFile compares device and inode numbers on Unix; it never matches
+// for filesystems that aren't backed by the OS, which have no links to follow.
+func (a *ancestor) contains(info fs.FileInfo) bool {
+	for ; a != nil; a = a.parent {
+		if os.SameFile(a.info, info) {
+			return true
+		}
+	}
+	return false
+}
+
+// Build the tree of fsys as a node called name, reading up to opts.Jobs
+// directories concurrently. The result doesn't depend on the number of jobs,
+// but fsys must be safe for concurrent use when there's more than one.
+func walkTree(fsys fs.FS, name string, opts *Options, filter *Filter) (*TreeNode, error) {
+	w := &treeWalker{
+		fsys:   fsys,
+		root:   name,
+		opts:   opts,
+		filter: filter,
+	}
+	if opts.Jobs > 1 {
+		w.slots = make(chan struct{}, opts.Jobs-1)
+	}
+	return w.getTreeNode(".", name, 1, nil)
+}
+
+// Build the tree of dir, a directory within fsys, as a node called name.
+// depth is the depth of dir starting at 1, and paths passed to the filter
+// start with the root name, e.g. "sub/skip" for -d sub, so exclude rules and
+// .gitignore patterns are relative to the working directory.
+func (w *treeWalker) getTreeNode(dir string, name string, depth int, parents *ancestor) (*TreeNode, error) {
+	// Create node
+	node := TreeNode{
+		Name:  name,
+		IsDir: true,
+		Depth: depth - 1,
+	}
+	info, err := fs.Stat(w.fsys, dir)
+	if err == nil {
+		node.Mode = info.Mode()
+		node.ModTime = info.ModTime()
+		if w.opts.Metadata {
+			node.setMetadata(info)
+		}
+		if parents.contains(info) {
+			node.Recursive = true
+			return &node, nil
+		}
+	}
+
+	// Check if max depth is exceeded
+	if w.opts.MaxDepth > 0 && depth > w.opts.MaxDepth {
+		// Return directory itself without recursively getting its contents,
+		// which still count towards its size like they do for path lists
+		node.Size = w.getDirSize(dir)
+		return &node, nil
+	}
+
+	files, err := w.readDir(dir)
+	if err != nil {
+		err = w.getUserPathError(err)
+		// Only an unreadable root is fatal, other directories are marked
+		if depth == 1 {
+			return nil, err
+		}
+		node.Err = err
+		return &node, nil
+	}
+	if info != nil && w.opts.FollowLinks {
+		parents = &ancestor{info, parents}
+	}
+
+	// Children are filled in by position so the order doesn't depend on
+	// which subdirectories are read concurrently
+	children := make([]*TreeNode, len(files))
+	errs := make([]error, len(files))
+	var wg sync.WaitGroup
+
+	// Process child entries
+	for i, entry := range files {
+		// Check if it's a hidden file
+		if w.opts.HideHidden && strings.HasPrefix(entry.Name(), ".") {
+			node.Filtered++
+			continue
+		}
+
+		childPath := path.Join(dir, entry.Name())
+
+		// Links are shown with their target, and followed into directories
+		// with FollowLinks
+		isDir := entry.IsDir()
+		isLink := entry.Type()&fs.ModeSymlink != 0
+		var target fs.FileInfo
+		if isLink {
+			target, _ = fs.Stat(w.fsys, childPath)
+			isDir = w.opts.FollowLinks && target != nil && target.IsDir()
+		}
+
+		if w.filter.ShouldExclude(entry.Name(), isDir, path.Join(w.root, childPath)) {
+			node.Filtered++
+			continue
+		}
+
+		if isDir {
+			// Read the subdirectory in another goroutine if a slot is free,
+			// and in this one otherwise
+			select {
+			case w.slots <- struct{}{}:
+				wg.Add(1)
+				go func(i int, entry fs.DirEntry) {
+					defer wg.Done()
+					children[i], errs[i] = w.getTreeNode(childPath, entry.Name(), depth+1, parents)
+					if isLink && children[i] != nil {
+						w.setLink(children[i], entry, childPath, target)
+					}
+					<-w.slots
+				}(i, entry)
+			default:
+				children[i], errs[i] = w.getTreeNode(childPath, entry.Name(), depth+1, parents)
+				if isLink && children[i] != nil {
+					w.setLink(children[i], entry, childPath, target)
+				}
+			}
+		} else {
+			child := &TreeNode{
+				Name:  entry.Name(),
+				IsDir: false,
+				Depth: depth,
+			}
+			if info, e := entry.Info(); e == nil {
+				child.Size = info.Size()
+				child.Mode = info.Mode()
+				child.ModTime = info.ModTime()
+				if w.opts.Metadata {
+					child.setMetadata(info)
+				}
+			}
+			if isLink {
+				w.setLink(child, entry, childPath, target)
+				// Followed links to files count with the size of their target
+				if w.opts.FollowLinks && target != nil {
+					child.Size = target.Size()
+				}
+			}
+			children[i] = child
+		}
+	}
+	wg.Wait()
+
+	for i, child := range children {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		if child == nil {
+			continue
+		}
+		node.Size += child.Size
+		// Files hidden by dirs-only still count towards their directory's size
+		if child.IsDir || !w.opts.DirsOnly {
+			node.Children = append(node.Children, child)
+		} else {
+			node.Filtered++
+		}
+	}
+
+	return &node, nil
+}
+
+// Rewrite the path of a filesystem error from one relative to the scanned
+// directory, e.g. "secret" or ".", to the path as the user wrote it, e.g.
+// "sub/secret" for -d sub
+func (w *treeWalker) getUserPathError(err error) error {
+	var pathErr *fs.PathError
+	if !errors.As(err, &pathErr) {
+		return err
+	}
+	return &fs.PathError{Op: pathErr.Op, Path: path.Join(w.root, pathErr.Path), Err: pathErr.Err}
+}
+
+// Add up the sizes of the files below dir, which aren't listed because of
+// MaxDepth. The hidden and exclude filters apply as they do to listed
+// entries, links aren't followed and unreadable directories count as empty.
+func (w *treeWalker) getDirSize(dir string) int64 {
+	entries, err := fs.ReadDir(w.fsys, dir)
+	if err != nil {
+		return 0
+	}
+
+	var size int64
+	for _, entry := range entries {
+		childPath := path.Join(dir, entry.Name())
+		if (w.opts.HideHidden && strings.HasPrefix(entry.Name(), ".")) || w.filter.ShouldExclude(entry.Name(), entry.IsDir(), path.Join(w.root, childPath)) {
+			continue
+		}
+		if entry.IsDir() {
+			size += w.getDirSize(childPath)
+		} else if info, err := entry.Info(); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
+
+// Read the entries of dir, sorted by name unless the sort order is "none",
+// which keeps the order of the filesystem
+func (w *treeWalker) readDir(dir string) ([]fs.DirEntry, error) {
+	if w.opts.Sort != "none" {
+		return fs.ReadDir(w.fsys, dir)
+	}
+
+	f, err := w.fsys.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	rd, ok := f.(fs.ReadDirFile)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: dir, Err: errors.New("not a directory")}
+	}
+	return rd.ReadDir(-1)
+}
+
+// Describe the symbolic link entry at name, whose target is described by
+// target (nil if it doesn't exist). The link text is left empty if fsys
+// can't read links.
+func (w *treeWalker) setLink(node *TreeNode, entry fs.DirEntry, name string, target fs.FileInfo) {
+	if info, err := entry.Info(); err == nil {
+		node.Mode = info.Mode()
+	}
+	if lfs, ok := w.fsys.(ReadLinkFS); ok {
+		node.LinkTarget, _ = lfs.ReadLink(name)
+	}
+	node.LinkBroken = target == nil
+}
+
+func (t *TreeNode) getAllNodes() []*TreeNode {
+	nodes := []*TreeNode{t}
+	for _, child := range t.Children {
+		nodes = append(nodes, child.getAllNodes()...)
+	}
+	return nodes
+}
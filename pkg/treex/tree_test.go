@@ -0,0 +1,270 @@
+package treex
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestGetRelativePath(t *testing.T) {
+	testCases := []struct {
+		absolute string
+		root     string
+		expected string
+	}{
+		{"/home/user/project/file.txt", "/home/user/", "project/file.txt"},
+		{"/home/user/project/", "/home/user/", "project"},
+		{"/home/user/project", "/home/user", "project"},
+		{"/other/path", "/home/user/", "/other/path"},
+		{"project/file.txt", "/home/user/", "project/file.txt"},
+	}
+
+	for _, tc := range testCases {
+		result := getRelativePath(tc.absolute, tc.root)
+		if result != tc.expected {
+			t.Errorf("For %s and %s, expected %s, but got %s", tc.absolute, tc.root, tc.expected, result)
+		}
+	}
+}
+
+func TestTreeNode(t *testing.T) {
+	// Create a test tree
+	root := &TreeNode{
+		Name:  "root",
+		IsDir: true,
+		Depth: 0,
+	}
+
+	child1 := &TreeNode{
+		Name:  "child1",
+		IsDir: true,
+		Depth: 1,
+	}
+
+	child2 := &TreeNode{
+		Name:  "child2",
+		IsDir: false,
+		Depth: 1,
+	}
+
+	grandchild1 := &TreeNode{
+		Name:  "grandchild1",
+		IsDir: false,
+		Depth: 2,
+	}
+
+	// Build tree structure
+	root.Children = append(root.Children, child1, child2)
+	child1.Children = append(child1.Children, grandchild1)
+
+	// Test node count
+	nodes := root.getAllNodes()
+	if len(nodes) != 4 {
+		t.Errorf("Expected 4 nodes, but got %d", len(nodes))
+	}
+
+	// Test specific node presence in result
+	found := false
+	for _, node := range nodes {
+		if node.Name == "grandchild1" {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Error("Did not find grandchild1 in all nodes")
+	}
+}
+
+func TestGetTreeNode(t *testing.T) {
+	// Create test directory structure
+	fsys := fstest.MapFS{
+		"dir1/subdir":        {Mode: fs.ModeDir | 0755},
+		"dir2":               {Mode: fs.ModeDir | 0755},
+		".hidden_dir":        {Mode: fs.ModeDir | 0755},
+		"file1.txt":          {Data: []byte("test")},
+		"dir1/file2.txt":     {Data: []byte("test")},
+		".hidden_file":       {Data: []byte("test")},
+		"dir2/excluded.log":  {Data: []byte("log")},
+		"dir1/subdir/a.json": {Data: []byte("{}")},
+	}
+
+	// Test basic tree generation
+	filter := NewFilter("", false)
+	node, err := walkTree(fsys, "test_dir_structure", &Options{}, filter)
+	if err != nil {
+		t.Fatalf("walkTree error: %v", err)
+	}
+
+	// Check root node
+	if node.Name != "test_dir_structure" || !node.IsDir {
+		t.Errorf("Root node error: name=%s, isDir=%v", node.Name, node.IsDir)
+	}
+
+	// Check child node count (should include 5: dir1, dir2, .hidden_dir, file1.txt, .hidden_file)
+	if len(node.Children) != 5 {
+		t.Errorf("Expected 5 child nodes, but got %d", len(node.Children))
+	}
+
+	// Check directory sizes are the sum of their contents
+	if node.Size != 17 {
+		t.Errorf("Expected root size 17, but got %d", node.Size)
+	}
+
+	// Test hidden file filtering
+	node, err = walkTree(fsys, "test_dir_structure", &Options{HideHidden: true}, filter)
+	if err != nil {
+		t.Fatalf("walkTree error: %v", err)
+	}
+
+	// Check child node count (should exclude .hidden_dir and .hidden_file)
+	if len(node.Children) != 3 {
+		t.Errorf("Expected 3 child nodes after filtering hidden files, but got %d", len(node.Children))
+	}
+
+	// Test path filtering, with paths starting with the root name
+	node, err = walkTree(fsys, "test_dir_structure", &Options{}, NewFilter("test_dir_structure/dir2/excluded*, test_dir_structure/dir1/subdir", false))
+	if err != nil {
+		t.Fatalf("walkTree error: %v", err)
+	}
+	for _, n := range allChildrenRecursive(node) {
+		if n.Name == "excluded.log" || n.Name == "subdir" {
+			t.Errorf("Node %s should be excluded by path", n.Name)
+		}
+	}
+
+	// Test maximum depth
+	node, err = walkTree(fsys, "test_dir_structure", &Options{MaxDepth: 1}, filter)
+	if err != nil {
+		t.Fatalf("walkTree error: %v", err)
+	}
+
+	// Find dir1 node
+	var dir1Node *TreeNode
+	for _, child := range node.Children {
+		if child.Name == "dir1" && child.IsDir {
+			dir1Node = child
+			break
+		}
+	}
+
+	if dir1Node == nil {
+		t.Fatal("Did not find dir1 node")
+	}
+
+	// Check if dir1 node has no children (due to depth limit)
+	if len(dir1Node.Children) != 0 {
+		t.Errorf("With depth limit 1, dir1 should have no children, but got %d", len(dir1Node.Children))
+	}
+	if !dir1Node.Mode.IsDir() {
+		t.Errorf("Truncated directories should keep their mode, got %v", dir1Node.Mode)
+	}
+
+	// Contents below the depth limit still count towards sizes, as they do
+	// for path lists
+	if dir1Node.Size != 6 || node.Size != 17 {
+		t.Errorf("Expected sizes 6 for dir1 and 17 for the root below the depth limit, got %d and %d", dir1Node.Size, node.Size)
+	}
+	var entries []pathEntry
+	for name, file := range fsys {
+		entries = append(entries, pathEntry{Path: name, IsDir: file.Mode.IsDir(), Size: int64(len(file.Data))})
+	}
+	listed := buildTreeFromPaths(entries, ".", &Options{MaxDepth: 1}, filter)
+	if listed.Size != node.Size {
+		t.Errorf("A path list should have the same size as the walk, got %d and %d", listed.Size, node.Size)
+	}
+	node, _ = walkTree(fsys, "test_dir_structure", &Options{MaxDepth: 1, HideHidden: true}, NewFilter(".json", false))
+	if node.Size != 11 {
+		t.Errorf("Filtered entries below the depth limit shouldn't count, got a root size of %d", node.Size)
+	}
+
+	// Test only directories
+	node, err = walkTree(fsys, "test_dir_structure", &Options{DirsOnly: true}, filter)
+	if err != nil {
+		t.Fatalf("walkTree error: %v", err)
+	}
+
+	// Check if only directories are included
+	for _, child := range node.Children {
+		if !child.IsDir {
+			t.Errorf("In dirs-only mode, node %s should not be a file", child.Name)
+		}
+	}
+
+	// Test a directory that doesn't exist
+	if _, err := walkTree(os.DirFS("missing"), "missing", &Options{}, filter); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
+
+// failingFS fails to read the directories in failing
+type failingFS struct {
+	fstest.MapFS
+	failing map[string]bool
+}
+
+func (f failingFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if f.failing[name] {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrPermission}
+	}
+	return f.MapFS.ReadDir(name)
+}
+
+func TestWalkTreeJobs(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for i := 0; i < 20; i++ {
+		for j := 0; j < 5; j++ {
+			fsys[fmt.Sprintf("d%02d/sub%d/file%d.txt", i, j, j)] = &fstest.MapFile{Data: make([]byte, i+j)}
+		}
+		fsys[fmt.Sprintf("f%02d.txt", i)] = &fstest.MapFile{Data: make([]byte, i)}
+	}
+
+	sequential, err := walkTree(fsys, "root", &Options{}, NewFilter("", false))
+	if err != nil {
+		t.Fatalf("walkTree error: %v", err)
+	}
+
+	// Concurrent walks produce the same tree, whatever the number of jobs
+	for _, jobs := range []int{2, 4, 64} {
+		concurrent, err := walkTree(fsys, "root", &Options{Jobs: jobs}, NewFilter("", false))
+		if err != nil {
+			t.Fatalf("walkTree error with %d jobs: %v", jobs, err)
+		}
+		if got, want := concurrent.ToJSONString(), sequential.ToJSONString(); got != want {
+			t.Errorf("With %d jobs, expected:\n%s\nBut got:\n%s", jobs, want, got)
+		}
+	}
+
+	// Unreadable directories are marked and the walk goes on
+	failing := failingFS{fsys, map[string]bool{"d03/sub1": true, "d12": true}}
+	for _, jobs := range []int{1, 8} {
+		node, err := walkTree(failing, "root", &Options{Jobs: jobs}, NewFilter("", false))
+		if err != nil {
+			t.Fatalf("walkTree error with %d jobs: %v", jobs, err)
+		}
+
+		errs := node.Errors()
+		if len(errs) != 2 || errs[0].Error() != "readdir root/d03/sub1: permission denied" || errs[1].Error() != "readdir root/d12: permission denied" {
+			t.Errorf("With %d jobs, expected errors for root/d03/sub1 and root/d12, but got %v", jobs, errs)
+		}
+		if len(node.getAllNodes()) != len(sequential.getAllNodes())-1-10 {
+			t.Errorf("With %d jobs, expected the other directories to be read", jobs)
+		}
+		if !strings.Contains(node.ToTreeString(true, "", false), "├── sub1/ [error opening dir]\n") {
+			t.Errorf("With %d jobs, expected sub1 to be marked", jobs)
+		}
+	}
+
+	// An unreadable root is fatal
+	_, err = walkTree(failingFS{fsys, map[string]bool{".": true}}, "root", &Options{}, NewFilter("", false))
+	if err == nil || err.Error() != "readdir root: permission denied" {
+		t.Errorf("Expected an error for an unreadable root, but got %v", err)
+	}
+	if !errors.Is(err, fs.ErrPermission) {
+		t.Error("Errors should keep their cause")
+	}
+}
@@ -0,0 +1,218 @@
+package treex
+
+import (
+	"fmt"
+	"hash/fnv"
+	"html"
+	"io"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	treemapWidth   = 1200
+	treemapHeight  = 800
+	treemapPadding = 2  // gap between a directory's border and its children
+	treemapHeader  = 14 // height of the directory label band
+)
+
+type treemapRect struct {
+	x, y, w, h float64
+}
+
+// ToTreemapSVG renders the tree as a squarified treemap where every rectangle
+// is sized by the byte size of its entry and files are colored by extension.
+func (t *TreeNode) ToTreemapSVG(width, height int) string {
+	return renderString(func(w io.Writer) {
+		t.writeTreemapSVG(w, width, height, "")
+	})
+}
+
+// Write the treemap, with desc as its description if not empty
+func (t *TreeNode) writeTreemapSVG(w io.Writer, width, height int, desc string) {
+	fmt.Fprintf(w, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\" font-family=\"sans-serif\" font-size=\"10\">\n", width, height, width, height)
+	if desc != "" {
+		fmt.Fprintf(w, "  <desc>%s</desc>\n", html.EscapeString(desc))
+	}
+	t.writeTreemapRects(w, treemapRect{0, 0, float64(width), float64(height)}, t.Name)
+	io.WriteString(w, "</svg>\n")
+}
+
+func (t *TreeNode) writeTreemapRects(w io.Writer, r treemapRect, path string) {
+	label := path
+	if t.LinkTarget != "" {
+		label += " -> " + t.LinkTarget
+	}
+	label += fmt.Sprintf(" (%s)", formatSize(t.Size))
+	for _, mark := range t.getMarks() {
+		label += " " + mark
+	}
+	title := html.EscapeString(label)
+
+	if !t.IsDir {
+		fmt.Fprintf(w, "  <rect x=\"%.2f\" y=\"%.2f\" width=\"%.2f\" height=\"%.2f\" fill=\"%s\" stroke=\"#ffffff\"><title>%s</title></rect>\n",
+			r.x, r.y, r.w, r.h, getExtensionColor(t.Name), title)
+		if r.w > 40 && r.h > 14 {
+			fmt.Fprintf(w, "  <text x=\"%.2f\" y=\"%.2f\" fill=\"#000000\">%s</text>\n", r.x+3, r.y+11, html.EscapeString(t.Name))
+		}
+		return
+	}
+
+	fmt.Fprintf(w, "  <rect x=\"%.2f\" y=\"%.2f\" width=\"%.2f\" height=\"%.2f\" fill=\"#eeeeee\" stroke=\"#999999\"><title>%s</title></rect>\n",
+		r.x, r.y, r.w, r.h, title)
+
+	// Reserve a label band for directories that are large enough to show one
+	inner := treemapRect{r.x + treemapPadding, r.y + treemapPadding, r.w - 2*treemapPadding, r.h - 2*treemapPadding}
+	if r.w > 40 && r.h > 2*treemapHeader {
+		fmt.Fprintf(w, "  <text x=\"%.2f\" y=\"%.2f\" fill=\"#333333\" font-weight=\"bold\">%s/</text>\n", r.x+3, r.y+11, html.EscapeString(t.Name))
+		inner.y += treemapHeader
+		inner.h -= treemapHeader
+	}
+	if inner.w <= 0 || inner.h <= 0 {
+		return
+	}
+
+	// Lay out non-empty children, largest first
+	var children []*TreeNode
+	for _, child := range t.Children {
+		if child.Size > 0 {
+			children = append(children, child)
+		}
+	}
+	sort.SliceStable(children, func(i, j int) bool {
+		return children[i].Size > children[j].Size
+	})
+
+	var total float64
+	for _, child := range children {
+		total += float64(child.Size)
+	}
+	if total == 0 {
+		return
+	}
+
+	areas := make([]float64, len(children))
+	scale := inner.w * inner.h / total
+	for i, child := range children {
+		areas[i] = float64(child.Size) * scale
+	}
+
+	for i, rect := range squarify(areas, inner) {
+		children[i].writeTreemapRects(w, rect, path+"/"+children[i].Name)
+	}
+}
+
+// squarify splits r into rectangles with the given areas (sorted in descending
+// order and summing to the area of r), keeping aspect ratios close to 1.
+func squarify(areas []float64, r treemapRect) []treemapRect {
+	rects := make([]treemapRect, 0, len(areas))
+	for len(areas) > 0 {
+		side := r.w
+		if r.h < side {
+			side = r.h
+		}
+
+		// Grow the current row while it improves the worst aspect ratio
+		n := 1
+		for n < len(areas) && worstRatio(areas[:n+1], side) <= worstRatio(areas[:n], side) {
+			n++
+		}
+
+		var sum float64
+		for _, a := range areas[:n] {
+			sum += a
+		}
+
+		if r.w >= r.h {
+			// Place the row as a column on the left
+			colWidth := sum / r.h
+			y := r.y
+			for _, a := range areas[:n] {
+				h := a / colWidth
+				rects = append(rects, treemapRect{r.x, y, colWidth, h})
+				y += h
+			}
+			r.x += colWidth
+			r.w -= colWidth
+		} else {
+			// Place the row along the top
+			rowHeight := sum / r.w
+			x := r.x
+			for _, a := range areas[:n] {
+				w := a / rowHeight
+				rects = append(rects, treemapRect{x, r.y, w, rowHeight})
+				x += w
+			}
+			r.y += rowHeight
+			r.h -= rowHeight
+		}
+		areas = areas[n:]
+	}
+	return rects
+}
+
+// worstRatio returns the largest aspect ratio of a row of areas laid along side
+func worstRatio(row []float64, side float64) float64 {
+	var sum, maxArea, minArea float64
+	minArea = row[0]
+	for _, a := range row {
+		sum += a
+		if a > maxArea {
+			maxArea = a
+		}
+		if a < minArea {
+			minArea = a
+		}
+	}
+	if sum == 0 || minArea == 0 {
+		return 0
+	}
+	side2 := side * side
+	sum2 := sum * sum
+	ratio := side2 * maxArea / sum2
+	if inverse := sum2 / (side2 * minArea); inverse > ratio {
+		ratio = inverse
+	}
+	return ratio
+}
+
+// Get a stable fill color for a file extension
+func getExtensionColor(name string) string {
+	extension := strings.ToLower(filepath.Ext(name))
+	if extension == "" {
+		return "#bbbbbb"
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(extension))
+	hue := float64(h.Sum32() % 360)
+	r, g, b := hslToRGB(hue, 0.55, 0.65)
+	return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+}
+
+func hslToRGB(h, s, l float64) (uint8, uint8, uint8) {
+	c := (1 - math.Abs(2*l-1)) * s
+	hp := h / 60
+	x := c * (1 - math.Abs(math.Mod(hp, 2)-1))
+
+	var r, g, b float64
+	switch {
+	case hp < 1:
+		r, g, b = c, x, 0
+	case hp < 2:
+		r, g, b = x, c, 0
+	case hp < 3:
+		r, g, b = 0, c, x
+	case hp < 4:
+		r, g, b = 0, x, c
+	case hp < 5:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+
+	m := l - c/2
+	return uint8((r + m) * 255), uint8((g + m) * 255), uint8((b + m) * 255)
+}
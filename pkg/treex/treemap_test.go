@@ -0,0 +1,77 @@
+package treex
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSquarify(t *testing.T) {
+	bounds := treemapRect{0, 0, 600, 400}
+	areas := []float64{60000, 60000, 40000, 30000, 20000, 20000, 10000}
+
+	rects := squarify(areas, bounds)
+	if len(rects) != len(areas) {
+		t.Fatalf("Expected %d rectangles, but got %d", len(areas), len(rects))
+	}
+
+	for i, r := range rects {
+		// Each rectangle should have the requested area
+		if math.Abs(r.w*r.h-areas[i]) > 0.01 {
+			t.Errorf("Rectangle %d: expected area %.2f, but got %.2f", i, areas[i], r.w*r.h)
+		}
+
+		// And stay inside the bounds
+		if r.x < -0.01 || r.y < -0.01 || r.x+r.w > bounds.w+0.01 || r.y+r.h > bounds.h+0.01 {
+			t.Errorf("Rectangle %d is out of bounds: %+v", i, r)
+		}
+	}
+}
+
+func TestToTreemapSVG(t *testing.T) {
+	tree := createTestTree()
+	tree.Children[0].Children[0].Size = 300 // file2.go
+	tree.Children[0].Size = 300             // dir1
+	tree.Children[1].Size = 100             // file1.txt
+	tree.Size = 400
+
+	result := tree.ToTreemapSVG(treemapWidth, treemapHeight)
+
+	expectedPatterns := []string{
+		"<svg xmlns=\"http://www.w3.org/2000/svg\"",
+		"<title>root (400B)</title>",
+		"<title>root/dir1 (300B)</title>",
+		"<title>root/dir1/file2.go (300B)</title>",
+		"<title>root/file1.txt (100B)</title>",
+		"</svg>",
+	}
+
+	for _, pattern := range expectedPatterns {
+		if !strings.Contains(result, pattern) {
+			t.Errorf("Treemap output missing expected pattern: %s", pattern)
+		}
+	}
+
+	// Files with the same extension share a color
+	if getExtensionColor("a.go") != getExtensionColor("b.GO") {
+		t.Error("Files with the same extension should have the same color")
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	testCases := []struct {
+		size     int64
+		expected string
+	}{
+		{0, "0B"},
+		{1023, "1023B"},
+		{1536, "1.5K"},
+		{5 * 1024 * 1024, "5.0M"},
+	}
+
+	for _, tc := range testCases {
+		if result := formatSize(tc.size); result != tc.expected {
+			t.Errorf("For %d, expected %s, but got %s", tc.size, tc.expected, result)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/shiquda/treex/pkg/treex"
+	flag "github.com/spf13/pflag"
+)
+
+// Run the scaffold subcommand: treex scaffold [options] <diagram>. The
+// diagram is a tree, indent or md listing ("-" reads stdin) whose entries are
+// created under the directory given by -d. Returns the process exit code.
+func runScaffold(args []string) int {
+	opts := &Options{}
+	fs := newFlagSet("treex scaffold", opts)
+	dryRun := fs.Bool("dry-run", false, "print what would be created without touching the disk (default: false)")
+	overwrite := fs.Bool("overwrite", false, "truncate existing files instead of skipping them (default: false)")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		fs.Usage()
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "error: usage: treex scaffold [options] <diagram>\n")
+		return 2
+	}
+
+	var content []byte
+	var err error
+	if fs.Arg(0) == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(fs.Arg(0))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error reading diagram: %s\n", err)
+		return 2
+	}
+
+	node, err := treex.ParseListing(string(content), "")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error parsing diagram: %s\n", err)
+		return 2
+	}
+
+	if err := scaffoldTree(node, opts.Dir, *dryRun, *overwrite, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		return 2
+	}
+	return 0
+}
+
+// Create the children of root under target, which stands for the root entry
+// of the diagram. Existing files are kept unless overwrite is set, and
+// summary entries of limited listings are skipped. Every action is reported
+// to w.
+func scaffoldTree(root *treex.TreeNode, target string, dryRun bool, overwrite bool, w io.Writer) error {
+	// Validate every path before creating anything
+	var validate func(node *treex.TreeNode, nodePath string) error
+	validate = func(node *treex.TreeNode, nodePath string) error {
+		if !filepath.IsLocal(filepath.FromSlash(nodePath)) {
+			return fmt.Errorf("refusing to create %q outside of the target directory", nodePath)
+		}
+		for _, child := range node.Children {
+			if err := validate(child, nodePath+"/"+child.Name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, child := range root.Children {
+		if err := validate(child, child.Name); err != nil {
+			return err
+		}
+	}
+
+	prefix := ""
+	if dryRun {
+		prefix = "would "
+	}
+
+	var create func(node *treex.TreeNode, nodePath string) error
+	create = func(node *treex.TreeNode, nodePath string) error {
+		diskPath := filepath.Join(target, filepath.FromSlash(nodePath))
+
+		// Summaries like "… 3 more files" from --max-entries don't say what
+		// to create
+		if node.Elided > 0 {
+			fmt.Fprintf(w, "skip: %s (entries left out of the diagram)\n", diskPath)
+			return nil
+		}
+
+		info, statErr := os.Stat(diskPath)
+		exists := statErr == nil
+
+		if node.IsDir {
+			switch {
+			case exists && !info.IsDir():
+				return fmt.Errorf("%s exists and is not a directory", diskPath)
+			case exists:
+				fmt.Fprintf(w, "exists: %s/\n", diskPath)
+			default:
+				fmt.Fprintf(w, "%screate: %s/\n", prefix, diskPath)
+				if !dryRun {
+					if err := os.MkdirAll(diskPath, 0755); err != nil {
+						return err
+					}
+				}
+			}
+
+			for _, child := range node.Children {
+				if err := create(child, nodePath+"/"+child.Name); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		switch {
+		case exists && info.IsDir():
+			return fmt.Errorf("%s exists and is a directory", diskPath)
+		case exists && !overwrite:
+			fmt.Fprintf(w, "skip: %s (exists)\n", diskPath)
+			return nil
+		case exists:
+			fmt.Fprintf(w, "%soverwrite: %s\n", prefix, diskPath)
+		default:
+			fmt.Fprintf(w, "%screate: %s\n", prefix, diskPath)
+		}
+		if dryRun {
+			return nil
+		}
+
+		// Files may be listed without their parent directories
+		if err := os.MkdirAll(filepath.Dir(diskPath), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(diskPath, nil, 0644)
+	}
+
+	for _, child := range root.Children {
+		if err := create(child, child.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
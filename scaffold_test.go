@@ -0,0 +1,112 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shiquda/treex/pkg/treex"
+)
+
+func TestScaffoldTree(t *testing.T) {
+	tempDir := t.TempDir()
+
+	root, err := treex.ParseListing(`project/
+├── cmd/
+│   └── main.go
+├── docs/
+└── README.md
+`, "")
+	if err != nil {
+		t.Fatalf("ParseListing error: %v", err)
+	}
+
+	// Dry run doesn't touch the disk
+	var out strings.Builder
+	if err := scaffoldTree(root, tempDir, true, false, &out); err != nil {
+		t.Fatalf("scaffoldTree error: %v", err)
+	}
+	if entries, _ := os.ReadDir(tempDir); len(entries) != 0 {
+		t.Errorf("Dry run should not create anything, found %d entries", len(entries))
+	}
+	if !strings.Contains(out.String(), "would create: "+filepath.Join(tempDir, "cmd", "main.go")) {
+		t.Errorf("Dry run should report planned actions:\n%s", out.String())
+	}
+
+	// Create the tree
+	if err := scaffoldTree(root, tempDir, false, false, io.Discard); err != nil {
+		t.Fatalf("scaffoldTree error: %v", err)
+	}
+	for _, dir := range []string{"cmd", "docs"} {
+		if info, err := os.Stat(filepath.Join(tempDir, dir)); err != nil || !info.IsDir() {
+			t.Errorf("Directory %s should be created", dir)
+		}
+	}
+	for _, file := range []string{filepath.Join("cmd", "main.go"), "README.md"} {
+		if info, err := os.Stat(filepath.Join(tempDir, file)); err != nil || info.IsDir() {
+			t.Errorf("File %s should be created", file)
+		}
+	}
+
+	// Existing files are kept by default
+	readme := filepath.Join(tempDir, "README.md")
+	os.WriteFile(readme, []byte("keep me"), 0644)
+	out.Reset()
+	if err := scaffoldTree(root, tempDir, false, false, &out); err != nil {
+		t.Fatalf("scaffoldTree error: %v", err)
+	}
+	if content, _ := os.ReadFile(readme); string(content) != "keep me" {
+		t.Error("Existing files should not be overwritten by default")
+	}
+	if !strings.Contains(out.String(), "skip: "+readme+" (exists)") {
+		t.Errorf("Skipped files should be reported:\n%s", out.String())
+	}
+
+	// And truncated with overwrite
+	if err := scaffoldTree(root, tempDir, false, true, io.Discard); err != nil {
+		t.Fatalf("scaffoldTree error: %v", err)
+	}
+	if content, _ := os.ReadFile(readme); len(content) != 0 {
+		t.Error("Existing files should be overwritten with overwrite")
+	}
+}
+
+func TestScaffoldTreeErrors(t *testing.T) {
+	tempDir := t.TempDir()
+
+	root, _ := treex.ParseListing("./\n├── ok.txt\n└── ../escape.txt\n", "")
+	if err := scaffoldTree(root, tempDir, false, false, io.Discard); err == nil {
+		t.Error("Should refuse paths outside of the target directory")
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "ok.txt")); err == nil {
+		t.Error("Nothing should be created when validation fails")
+	}
+
+	// A file where the diagram declares a directory
+	os.WriteFile(filepath.Join(tempDir, "cmd"), []byte("file"), 0644)
+	root, _ = treex.ParseListing("./\n└── cmd/\n", "")
+	if err := scaffoldTree(root, tempDir, false, false, io.Discard); err == nil {
+		t.Error("Should fail when a file is in the way of a directory")
+	}
+}
+
+func TestScaffoldLimitedListing(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// A listing made with --max-entries
+	root, _ := treex.ParseListing("project/\n├── cmd/\n│   ├── main.go\n│   └── … 3 more files\n└── … 1 .md, 2 directories\n", "")
+	var out strings.Builder
+	if err := scaffoldTree(root, tempDir, false, false, &out); err != nil {
+		t.Fatalf("scaffoldTree error: %v", err)
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(tempDir, "cmd"))
+	if top, _ := os.ReadDir(tempDir); len(top) != 1 || len(entries) != 1 || entries[0].Name() != "main.go" {
+		t.Errorf("Only cmd/main.go should be created, got %d entries in cmd and %d at the top", len(entries), len(top))
+	}
+	if !strings.Contains(out.String(), "skip: "+filepath.Join(tempDir, "cmd", "… 3 more files")+" (entries left out of the diagram)") {
+		t.Errorf("Summary entries should be reported as skipped:\n%s", out.String())
+	}
+}